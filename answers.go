@@ -0,0 +1,162 @@
+package prompt
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"gopkg.in/yaml.v3"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+)
+
+// AnswerProvider supplies responses to prompts before they are shown. Prompts with an ID ask their provider for an
+// answer first and only ask the user when there isn't one.
+type AnswerProvider interface {
+	// Answer returns the response for the prompt with the given ID, or false if there isn't one.
+	Answer(id string) (string, bool, error)
+}
+
+// DefaultAnswerProvider is used by every prompt that doesn't specify its own AnswerProvider. Nil means that prompts
+// always ask the user.
+var DefaultAnswerProvider AnswerProvider
+
+const defaultEnvAnswerPrefix = "PROMPT_ANSWER_"
+
+// EnvAnswerProvider reads answers from environment variables. The variable for a prompt is the prefix followed by its
+// ID in upper case, with anything other than letters and digits replaced by underscores.
+type EnvAnswerProvider struct {
+	// The prefix of every variable
+	// Default is "PROMPT_ANSWER_"
+	Prefix string
+}
+
+func (e EnvAnswerProvider) Answer(id string) (string, bool, error) {
+	answer, ok := os.LookupEnv(e.variable(id))
+	return answer, ok, nil
+}
+
+func (e EnvAnswerProvider) variable(id string) string {
+	prefix := e.Prefix
+	if prefix == "" {
+		prefix = defaultEnvAnswerPrefix
+	}
+
+	name := strings.Map(func(r rune) rune {
+		if r > unicode.MaxASCII || !(unicode.IsLetter(r) || unicode.IsDigit(r)) {
+			return '_'
+		}
+
+		return unicode.ToUpper(r)
+	}, id)
+
+	return prefix + name
+}
+
+// MapAnswerProvider supplies answers from a map of prompt IDs to responses.
+type MapAnswerProvider map[string]string
+
+func (m MapAnswerProvider) Answer(id string) (string, bool, error) {
+	answer, ok := m[id]
+	return answer, ok, nil
+}
+
+// NewFileAnswerProvider reads answers from a JSON or YAML file that maps prompt IDs to responses. Lists become comma
+// separated responses for MultiSelect and booleans become "yes" or "no".
+func NewFileAnswerProvider(path string) (AnswerProvider, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read answers file: %w", err)
+	}
+
+	var values map[string]any
+	if strings.EqualFold(filepath.Ext(path), ".json") {
+		err = json.Unmarshal(content, &values)
+	} else {
+		err = yaml.Unmarshal(content, &values)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("couldn't parse answers file: %w", err)
+	}
+
+	answers := make(MapAnswerProvider, len(values))
+	for id, value := range values {
+		answers[id] = answerString(value)
+	}
+
+	return answers, nil
+}
+
+func answerString(value any) string {
+	switch value := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return value
+	case bool:
+		if value {
+			return "yes"
+		}
+
+		return "no"
+	case []any:
+		items := make([]string, 0, len(value))
+		for _, item := range value {
+			items = append(items, answerString(item))
+		}
+
+		return strings.Join(items, ",")
+	default:
+		return fmt.Sprint(value)
+	}
+}
+
+// lookupAnswer asks the provider, or DefaultAnswerProvider if it is nil, for the response to the prompt.
+func (b *base) lookupAnswer(id string, provider AnswerProvider) (string, bool, error) {
+	if id == "" {
+		return "", false, nil
+	}
+
+	if provider == nil {
+		provider = DefaultAnswerProvider
+	}
+
+	if provider == nil {
+		return "", false, nil
+	}
+
+	answer, ok, err := provider.Answer(id)
+	if err != nil {
+		return "", false, fmt.Errorf("couldn't get answer for %q: %w", id, err)
+	}
+
+	return answer, ok, nil
+}
+
+// finishWithResponse completes a prompt whose response wasn't typed into the interactive prompt, either because it was
+// supplied up front or because it was read as a line. The finished prompt is rendered so that the output shows what
+// the response was.
+func (b *base) finishWithResponse(err error, question string, render func(isFinished bool), responseText func() string) error {
+	if b.isLineMode() {
+		if err == nil {
+			err = b.writeLines(question, responseText())
+		}
+
+		return b.finishLines(err)
+	}
+
+	if err != nil {
+		b.promptState = Waiting
+		closeErr := b.closeTerminal()
+		if closeErr != nil {
+			return errors.Join(err, closeErr)
+		}
+
+		return err
+	}
+
+	render(true)
+	b.finish()
+	return b.closeErr
+}
@@ -1,9 +1,8 @@
 package prompt
 
 import (
+	"context"
 	"errors"
-	"fmt"
-	"github.com/eiannone/keyboard"
 )
 
 type State int
@@ -15,96 +14,279 @@ const (
 )
 
 type base struct {
-	output *output
-	promptState  State
+	terminal    Terminal
+	output      *output
+	promptState State
+
+	// Set instead of output when the terminal can't read individual keys
+	lineTerminal LineTerminal
+
+	// Receives a value when the terminal is resized. Nil when the terminal can't tell.
+	resizes <-chan struct{}
+
+	// Called after the terminal is resized so that the prompt can lay itself out for the new width and redraw.
+	resizeFunc func()
+
+	// Returns whether the user hasn't typed anything, in which case Ctrl-D ends the input. Nil when Ctrl-D is an
+	// ordinary key.
+	isInputEmptyFunc func() bool
+
+	// Replaces the frame when the prompt stops without being submitted, so that the input isn't left on the terminal.
+	// Nil when the last frame can be left as it is.
+	hideInputFunc func()
+
+	// The error from closing the terminal when the prompt finished
+	closeErr error
+
+	// Whether the question has been written without the interactive prompt and is waiting for the response to be
+	// written after it
+	isQuestionLineWritten bool
+
+	theme *Theme
 }
 
-func (b *base) show() error {
+// errResized cancels reading a key when the terminal is resized.
+var errResized = errors.New("terminal was resized")
+
+func (b *base) show(ctx context.Context, terminal Terminal, theme *Theme) error {
+	if err := ctx.Err(); err != nil {
+		return err
+	}
+
 	if b.promptState == Showing {
-		return errors.New("cannot show a prompt multiple times")
+		return stateError("cannot show a prompt multiple times")
 	}
 
 	if b.promptState == Finished {
-		return errors.New("cannot show a finished prompt")
+		return stateError("cannot show a finished prompt")
+	}
+
+	if terminal == nil {
+		terminal = DefaultTerminal
+	}
+
+	if theme == nil {
+		theme = &DefaultTheme
 	}
+	b.theme = theme
 
-	err := keyboard.Open()
+	b.terminal = terminal
+	b.lineTerminal = nil
+	b.closeErr = nil
+
+	if lineTerminal, ok := asLineTerminal(terminal); ok {
+		b.lineTerminal = lineTerminal
+		b.promptState = Showing
+		return nil
+	}
+
+	err := terminal.Open()
 	if err != nil {
-		return fmt.Errorf("can't listen to keyboard: %w", err)
+		return &TerminalError{Op: "open", Err: err}
 	}
 
-	output, err := newOutput()
+	output, err := newOutput(terminal)
 	if err != nil {
-		return err
+		terminal.Close()
+		return &TerminalError{Op: "get the size of", Err: err}
 	}
 
 	b.output = output
 	b.promptState = Showing
 
+	b.resizes = nil
+	if notifier, ok := terminal.(ResizeNotifier); ok {
+		b.resizes = notifier.Resized()
+	}
+
 	return nil
 }
 
 func (b *base) Pause() error {
 	if b.promptState == Waiting {
-		return errors.New("cannot pause a prompt when it is already waiting")
+		return stateError("cannot pause a prompt when it is already waiting")
 	}
 
 	if b.promptState == Finished {
-		return errors.New("cannot pause a finished prompt")
+		return stateError("cannot pause a finished prompt")
 	}
 
 	b.output.clear()
 	b.output.flush()
 	b.promptState = Waiting
 
-	err := keyboard.Close()
-	if err != nil {
-		panic(err)
-	}
-
-	return nil
+	return b.closeTerminal()
 }
 
 func (b *base) ResetToWaiting() error {
 	if b.promptState == Waiting {
-		return errors.New("cannot reshow a response that is waiting")
+		return stateError("cannot reshow a response that is waiting")
 	}
 
 	if b.promptState == Showing {
-		return errors.New("cannot reshow a response that is showing")
+		return stateError("cannot reshow a response that is showing")
 	}
 
-	b.output.uncommit()
+	if b.output != nil {
+		b.output.uncommit()
+	}
 	b.promptState = Waiting
 	return nil
 }
 
+// finish leaves the final frame of the prompt on the terminal. An error closing the terminal is kept in closeErr for
+// the prompt to return.
 func (b *base) finish() {
-	err := keyboard.Close()
+	b.closeErr = b.closeTerminal()
+	b.output.commit()
+	b.promptState = Finished
+}
+
+// closeTerminal gives the terminal back after the prompt stops being shown on it.
+func (b *base) closeTerminal() error {
+	err := b.terminal.Close()
 	if err != nil {
-		panic(err)
+		return &TerminalError{Op: "close", Err: err}
 	}
 
-	b.output.commit()
-	b.promptState = Finished
+	return nil
+}
+
+// restoreAfterPanic is deferred while a prompt is shown. Every return leaves the prompt waiting or finished, so one
+// that is still showing was stopped by a panic, such as from a callback. The terminal is restored so that it isn't left
+// in raw mode with a hidden cursor, and the panic carries on afterwards.
+func (b *base) restoreAfterPanic() {
+	if b.promptState != Showing {
+		return
+	}
+
+	b.promptState = Waiting
+	if b.isLineMode() {
+		return
+	}
+
+	if b.hideInputFunc != nil {
+		b.hideInputFunc()
+		b.output.showCursor()
+		b.output.commit()
+	} else {
+		b.output.restore()
+	}
+	b.terminal.Close()
+}
+
+// isLineMode returns whether the prompt is being shown without the interactive prompt.
+func (b *base) isLineMode() bool {
+	return b.lineTerminal != nil
 }
 
 func (b *base) State() State {
 	return b.promptState
 }
 
-func (b *base) nextKey() (Key, error) {
-	r, key, err := keyboard.GetKey()
-	if err != nil {
-		if err.Error() == "Unrecognized escape sequence" {
-			return b.nextKey()
+func (b *base) nextKey(ctx context.Context) (Key, error) {
+	for {
+		key, isResized, err := b.readKey(ctx)
+		if isResized {
+			b.resize()
+		}
+
+		if err == errResized {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if key == ControlCtrlC {
+			return nil, ErrInterrupted
+		}
+
+		if key == ControlCtrlD && b.isInputEmptyFunc != nil && b.isInputEmptyFunc() {
+			return nil, ErrEOF
+		}
+
+		if suspender, ok := b.terminal.(Suspender); ok && key == ControlCtrlZ && ShouldSuspendOnCtrlZ {
+			err := b.suspend(suspender)
+			if err != nil {
+				return nil, err
+			}
+
+			continue
+		}
+
+		return key, nil
+	}
+}
+
+// readKey reads the next key from the terminal while watching for the terminal to be resized. Reading is cancelled by
+// a resize, in which case errResized is returned.
+func (b *base) readKey(ctx context.Context) (Key, bool, error) {
+	if b.resizes == nil {
+		key, err := b.terminal.ReadKey(ctx)
+		return key, false, err
+	}
+
+	readCtx, cancel := context.WithCancel(ctx)
+
+	isResized := false
+	done := make(chan struct{})
+	go func() {
+		defer close(done)
+
+		select {
+		case <-b.resizes:
+			isResized = true
+			cancel()
+		case <-readCtx.Done():
+		}
+	}()
+
+	key, err := b.terminal.ReadKey(readCtx)
+	cancel()
+	<-done
+
+	if isResized && err != nil && ctx.Err() == nil {
+		err = errResized
+	}
+
+	return key, isResized, err
+}
+
+// resize lays out the prompt again if the size of the terminal has changed.
+func (b *base) resize() {
+	width, height, err := b.terminal.Size()
+	if err != nil || width <= 0 || width == b.output.outputWidth && height == b.output.outputHeight {
+		return
+	}
+
+	b.output.resize(width, height)
+	if b.resizeFunc != nil {
+		b.resizeFunc()
+	}
+}
+
+// abort stops showing the prompt after nextKey fails. If the context was cancelled then the prompt is removed from the
+// screen in the same way as Pause, so that it can be shown again later, and the context's error is returned.
+func (b *base) abort(ctx context.Context, err error) error {
+	if ctx.Err() == nil {
+		if b.hideInputFunc != nil {
+			b.hideInputFunc()
+		}
+
+		b.finish()
+		if b.closeErr != nil {
+			return errors.Join(err, b.closeErr)
 		}
-		return nil, fmt.Errorf("error getting key input: %w", err)
+
+		return err
 	}
 
-	if key == keyboard.KeyCtrlC {
-		return nil, errors.New("prompt loop aborted")
+	pauseErr := b.Pause()
+	if pauseErr != nil {
+		return pauseErr
 	}
 
-	return ToKey(r, key), nil
+	return ctx.Err()
 }
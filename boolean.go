@@ -1,6 +1,7 @@
 package prompt
 
 import (
+	"context"
 	editor "github.com/JosephNaberhaus/texteditor"
 	"strings"
 )
@@ -8,6 +9,13 @@ import (
 type Boolean struct {
 	base
 
+	// A stable identifier for the prompt. When it is set, the AnswerProvider is asked for a response before the prompt
+	// is shown to the user.
+	ID string
+
+	// Supplies responses to skip the prompt. Defaults to DefaultAnswerProvider.
+	AnswerProvider AnswerProvider
+
 	// The question to display to the user
 	Question string
 
@@ -18,31 +26,64 @@ type Boolean struct {
 	// Called when a key is pressed but before it is processed. Return `false` to cancel the event.
 	OnKeyFunc func(Prompt, Key) bool
 
+	// The terminal to show the prompt on. Defaults to DefaultTerminal.
+	Terminal Terminal
+
+	// The symbols and colors to draw the prompt with. Defaults to DefaultTheme.
+	Theme *Theme
+
 	editor *editor.TextEditor
 }
 
 // Show displays the prompt to the user and blocks the current Go routine until the user submits
 func (b *Boolean) Show() error {
-	err := b.show()
+	return b.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (b *Boolean) ShowContext(ctx context.Context) error {
+	err := b.show(ctx, b.Terminal, b.Theme)
 	if err != nil {
 		return err
 	}
+	defer b.restoreAfterPanic()
+
+	answer, hasAnswer, err := b.lookupAnswer(b.ID, b.AnswerProvider)
+	if err != nil || hasAnswer {
+		if err == nil {
+			err = b.setResponse(answer)
+		}
+
+		return b.finishWithResponse(err, b.Question, b.render, b.responseText)
+	}
+
+	if b.isLineMode() {
+		return b.showLines(ctx)
+	}
 
 	b.editor = editor.NewEditor()
 	b.editor.SetWidth(b.output.outputWidth)
+	b.resizeFunc = b.resize
+	b.isInputEmptyFunc = func() bool { return b.editor.String() == "" }
 	b.render(false)
 
 	for b.promptState == Showing {
-		nextKey, err := b.nextKey()
+		nextKey, err := b.nextKey(ctx)
 		if err != nil {
-			b.finish()
-			return err
+			return b.abort(ctx, err)
 		}
 
 		b.handleInput(nextKey)
 	}
 
-	return nil
+	return b.closeErr
+}
+
+// resize wraps the input to the new width of the terminal and redraws the prompt.
+func (b *Boolean) resize() {
+	b.editor.SetWidth(b.output.outputWidth)
+	b.render(false)
 }
 
 func (b *Boolean) handleInput(input Key) {
@@ -70,31 +111,54 @@ func (b *Boolean) handleInput(input Key) {
 func (b *Boolean) render(isFinished bool) {
 	b.output.clear()
 
-	b.output.writeColor("? ", colorGreen)
-	b.output.write(b.Question)
+	b.writeQuestion(b.Question)
 
 	if b.defaultResponse() {
-		b.output.writeColor("(Y/n) ", colorGreen)
+		b.output.writeStyle("(Y/n) ", b.theme.HintStyle)
 	} else {
-		b.output.writeColor(" (y/N) ", colorGreen)
+		b.output.writeStyle(" (y/N) ", b.theme.HintStyle)
 	}
 
 	b.editor.SetFirstLineIndent(b.output.cursorColumn)
 
 	if isFinished {
-		if b.Response() {
-			b.output.writeColor("Yes", colorCyan)
-		} else {
-			b.output.writeColor("No", colorCyan)
-		}
-	} else {
-		b.output.write(b.editor.String())
+		b.writeAnswer(b.responseText())
+		return
 	}
 
-	b.output.setCursor(b.editor.CursorRow(), b.editor.CursorColumn())
+	b.output.setCursor(b.output.writeEditor(b.editor, Style{}))
 	b.output.flush()
 }
 
+// showLines reads the response without the interactive prompt.
+func (b *Boolean) showLines(ctx context.Context) error {
+	line, err := b.readLine(ctx, b.Question)
+	if err == nil {
+		err = b.setResponse(line)
+	}
+
+	return b.finishWithResponse(err, b.Question, b.render, b.responseText)
+}
+
+// setResponse replaces the input with the response.
+func (b *Boolean) setResponse(response string) error {
+	b.editor = editor.NewEditor()
+	if b.output != nil {
+		b.editor.SetWidth(b.output.outputWidth)
+	}
+	b.editor.Write(response)
+
+	return nil
+}
+
+func (b *Boolean) responseText() string {
+	if b.Response() {
+		return "Yes"
+	}
+
+	return "No"
+}
+
 func (b *Boolean) defaultResponse() bool {
 	if b.IsTrueFunc != nil {
 		return b.IsTrueFunc("")
@@ -119,5 +183,5 @@ func (b *Boolean) Response() bool {
 
 func defaultIsYes(input string) bool {
 	lowercase := strings.ToLower(input)
-	return lowercase == "y" || lowercase == "Y"
+	return lowercase == "y" || lowercase == "yes"
 }
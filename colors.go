@@ -1,62 +1,324 @@
 package prompt
 
-import escapes "github.com/snugfox/ansi-escapes"
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Color is a color that text can be drawn in. It is either one of the basic terminal colors, an index into the
+// 256-color palette or a 24-bit RGB color. ColorDefault leaves the text in the terminal's own color.
+type Color uint32
 
-type color uint8
+// The basic terminal colors. Their exact shades are chosen by the terminal.
+const (
+	ColorDefault Color = iota
+	ColorBlack
+	ColorRed
+	ColorGreen
+	ColorYellow
+	ColorBlue
+	ColorMagenta
+	ColorCyan
+	ColorWhite
+)
 
+// The kind of color is stored in the top byte and its value in the rest.
 const (
-	colorBlack color = iota
-	colorRed
-	colorGreen
-	colorYellow
-	colorBlue
-	colorMagenta
-	colorCyan
-	colorWhite
+	colorKindMask  Color = 0xFF << 24
+	colorKindBasic Color = 0 << 24
+	colorKind256   Color = 1 << 24
+	colorKindRGB   Color = 2 << 24
 )
 
-func (c color) toTextEscapes() string {
-	switch c {
-	case colorBlack:
-		return escapes.TextColorBlack
-	case colorRed:
-		return escapes.TextColorRed
-	case colorGreen:
-		return escapes.TextColorGreen
-	case colorYellow:
-		return escapes.TextColorYellow
-	case colorBlue:
-		return escapes.TextColorBlue
-	case colorMagenta:
-		return escapes.TextColorMagenta
-	case colorCyan:
-		return escapes.TextColorCyan
-	case colorWhite:
-		return escapes.TextColorWhite
-	}
-
-	panic("invalid color")
-}
-
-func (c color) toBackgroundEscapes() string {
-	switch c {
-	case colorBlack:
-		return escapes.BackgroundColorBlack
-	case colorRed:
-		return escapes.BackgroundColorRed
-	case colorGreen:
-		return escapes.BackgroundColorGreen
-	case colorYellow:
-		return escapes.BackgroundColorYellow
-	case colorBlue:
-		return escapes.BackgroundColorBlue
-	case colorMagenta:
-		return escapes.BackgroundColorMagenta
-	case colorCyan:
-		return escapes.BackgroundColorCyan
-	case colorWhite:
-		return escapes.BackgroundColorWhite
-	}
-
-	panic("invalid color")
+// Color256 returns the color at the index of the 256-color palette. The first 16 are the basic colors and their bright
+// variants, followed by a 6x6x6 color cube and 24 shades of gray.
+func Color256(index uint8) Color {
+	return colorKind256 | Color(index)
+}
+
+// RGB returns a 24-bit color.
+func RGB(r, g, b uint8) Color {
+	return colorKindRGB | Color(r)<<16 | Color(g)<<8 | Color(b)
+}
+
+// ParseHexColor parses a 24-bit color written as "#rrggbb" or "#rgb". The "#" is optional.
+func ParseHexColor(hex string) (Color, error) {
+	digits := strings.TrimPrefix(hex, "#")
+	if len(digits) == 3 {
+		digits = string([]byte{digits[0], digits[0], digits[1], digits[1], digits[2], digits[2]})
+	}
+
+	if len(digits) != 6 {
+		return ColorDefault, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	value, err := strconv.ParseUint(digits, 16, 32)
+	if err != nil {
+		return ColorDefault, fmt.Errorf("invalid hex color %q", hex)
+	}
+
+	return RGB(uint8(value>>16), uint8(value>>8), uint8(value)), nil
+}
+
+// MustParseHexColor is like ParseHexColor but panics if the color is invalid. It is meant for colors that are written
+// into the program, such as the ones in a Theme.
+func MustParseHexColor(hex string) Color {
+	c, err := ParseHexColor(hex)
+	if err != nil {
+		panic(err)
+	}
+
+	return c
+}
+
+func (c Color) rgb() (r, g, b uint8) {
+	return uint8(c >> 16), uint8(c >> 8), uint8(c)
+}
+
+// paletteIndex returns the index of the color in the 256-color palette. Only valid for basic and 256 colors.
+func (c Color) paletteIndex() int {
+	if c&colorKindMask == colorKindBasic {
+		return int(c) - 1
+	}
+
+	return int(c & 0xFF)
+}
+
+// downgrade returns the closest color that the profile can show.
+func (c Color) downgrade(profile ColorProfile) Color {
+	if c == ColorDefault {
+		return c
+	}
+
+	switch c & colorKindMask {
+	case colorKindRGB:
+		if profile == ColorProfileTrueColor {
+			return c
+		}
+
+		index := nearestPaletteIndex(c.rgb())
+		if profile == ColorProfile256 {
+			return Color256(uint8(index))
+		}
+
+		return Color256(uint8(nearestBasicIndex(paletteRGB(index))))
+	case colorKind256:
+		if profile == ColorProfile256 || profile == ColorProfileTrueColor || c.paletteIndex() < 16 {
+			return c
+		}
+
+		return Color256(uint8(nearestBasicIndex(paletteRGB(c.paletteIndex()))))
+	}
+
+	return c
+}
+
+// sgrParameters returns the Select Graphic Rendition parameters that draw the color.
+func (c Color) sgrParameters(isBackground bool) string {
+	offset := 0
+	if isBackground {
+		offset = 10
+	}
+
+	switch c & colorKindMask {
+	case colorKindRGB:
+		r, g, b := c.rgb()
+		return fmt.Sprintf("%d;2;%d;%d;%d", 38+offset, r, g, b)
+	case colorKind256:
+		index := c.paletteIndex()
+		if index < 8 {
+			return strconv.Itoa(30 + offset + index)
+		} else if index < 16 {
+			return strconv.Itoa(90 + offset + index - 8)
+		}
+
+		return fmt.Sprintf("%d;5;%d", 38+offset, index)
+	}
+
+	return strconv.Itoa(30 + offset + c.paletteIndex())
+}
+
+// The colors that xterm uses for the first 16 entries of the palette. Terminals let users change them, so they are
+// only used to find the closest basic color.
+var basicPalette = [16][3]uint8{
+	{0, 0, 0}, {205, 0, 0}, {0, 205, 0}, {205, 205, 0}, {0, 0, 238}, {205, 0, 205}, {0, 205, 205}, {229, 229, 229},
+	{127, 127, 127}, {255, 0, 0}, {0, 255, 0}, {255, 255, 0}, {92, 92, 255}, {255, 0, 255}, {0, 255, 255}, {255, 255, 255},
+}
+
+// The levels of each channel in the 6x6x6 color cube of the palette
+var cubeLevels = [6]uint8{0, 95, 135, 175, 215, 255}
+
+// paletteRGB returns the color of an entry in the 256-color palette.
+func paletteRGB(index int) (uint8, uint8, uint8) {
+	switch {
+	case index < 16:
+		return basicPalette[index][0], basicPalette[index][1], basicPalette[index][2]
+	case index < 232:
+		index -= 16
+		return cubeLevels[index/36], cubeLevels[index/6%6], cubeLevels[index%6]
+	default:
+		gray := uint8(8 + 10*(index-232))
+		return gray, gray, gray
+	}
+}
+
+// nearestPaletteIndex returns the entry of the color cube or the grays that is closest to the color. The first 16
+// entries are skipped since they can be changed by the user.
+func nearestPaletteIndex(r, g, b uint8) int {
+	cubeIndex := 16 + 36*nearestCubeLevel(r) + 6*nearestCubeLevel(g) + nearestCubeLevel(b)
+
+	average := (int(r) + int(g) + int(b)) / 3
+	grayIndex := 232 + min(23, max(0, (average-3)/10))
+
+	cr, cg, cb := paletteRGB(cubeIndex)
+	gr, gg, gb := paletteRGB(grayIndex)
+	if colorDistance(r, g, b, gr, gg, gb) < colorDistance(r, g, b, cr, cg, cb) {
+		return grayIndex
+	}
+
+	return cubeIndex
+}
+
+func nearestCubeLevel(value uint8) int {
+	nearest := 0
+	for i, level := range cubeLevels {
+		if abs(int(level)-int(value)) < abs(int(cubeLevels[nearest])-int(value)) {
+			nearest = i
+		}
+	}
+
+	return nearest
+}
+
+// nearestBasicIndex returns the basic color, or its bright variant, that is closest to the color.
+func nearestBasicIndex(r, g, b uint8) int {
+	nearest := 0
+	for i, basic := range basicPalette {
+		if colorDistance(r, g, b, basic[0], basic[1], basic[2]) < colorDistance(r, g, b, basicPalette[nearest][0], basicPalette[nearest][1], basicPalette[nearest][2]) {
+			nearest = i
+		}
+	}
+
+	return nearest
+}
+
+func colorDistance(r1, g1, b1, r2, g2, b2 uint8) int {
+	dr, dg, db := int(r1)-int(r2), int(g1)-int(g2), int(b1)-int(b2)
+	return dr*dr + dg*dg + db*db
+}
+
+// Style is how text is drawn. The zero value draws text in the terminal's default style.
+type Style struct {
+	Foreground Color
+	Background Color
+
+	IsBold       bool
+	IsDim        bool
+	IsItalic     bool
+	IsUnderlined bool
+}
+
+// merge returns the style with the overlay applied on top of it. Colors in the overlay replace the style's colors and
+// attributes in either are kept.
+func (s Style) merge(overlay Style) Style {
+	if overlay.Foreground != ColorDefault {
+		s.Foreground = overlay.Foreground
+	}
+	if overlay.Background != ColorDefault {
+		s.Background = overlay.Background
+	}
+
+	s.IsBold = s.IsBold || overlay.IsBold
+	s.IsDim = s.IsDim || overlay.IsDim
+	s.IsItalic = s.IsItalic || overlay.IsItalic
+	s.IsUnderlined = s.IsUnderlined || overlay.IsUnderlined
+	return s
+}
+
+// escapes returns the escape sequence that switches to the style, using the colors that the profile can show.
+// Monochrome profiles don't use escape sequences at all.
+func (s Style) escapes(profile ColorProfile) string {
+	if profile == ColorProfileMonochrome {
+		return ""
+	}
+
+	var parameters []string
+	if s.IsBold {
+		parameters = append(parameters, "1")
+	}
+	if s.IsDim {
+		parameters = append(parameters, "2")
+	}
+	if s.IsItalic {
+		parameters = append(parameters, "3")
+	}
+	if s.IsUnderlined {
+		parameters = append(parameters, "4")
+	}
+	if s.Foreground != ColorDefault {
+		parameters = append(parameters, s.Foreground.downgrade(profile).sgrParameters(false))
+	}
+	if s.Background != ColorDefault {
+		parameters = append(parameters, s.Background.downgrade(profile).sgrParameters(true))
+	}
+
+	if len(parameters) == 0 {
+		return ""
+	}
+
+	return "\033[" + strings.Join(parameters, ";") + "m"
+}
+
+// ColorProfile is the range of colors that a terminal can show.
+type ColorProfile int
+
+const (
+	// ColorProfileMonochrome draws everything in the terminal's default style without any escape sequences. Prompts
+	// use text markers to show what would otherwise be shown with color.
+	ColorProfileMonochrome ColorProfile = iota
+
+	// ColorProfileBasic is the 8 basic colors and their bright variants.
+	ColorProfileBasic
+
+	// ColorProfile256 is the 256-color palette.
+	ColorProfile256
+
+	// ColorProfileTrueColor is every 24-bit color.
+	ColorProfileTrueColor
+)
+
+// DefaultColorProfile is the range of colors that prompts draw with. Colors that are outside of it are replaced by the
+// closest color inside of it. It is detected from the environment when the program starts. Set it to
+// ColorProfileMonochrome to turn off colors.
+var DefaultColorProfile = DetectColorProfile()
+
+// DetectColorProfile guesses the range of colors that the terminal supports from the COLORTERM and TERM environment
+// variables. Colors are turned off when NO_COLOR is set to anything other than an empty string, following
+// https://no-color.org, or when the terminal is dumb.
+func DetectColorProfile() ColorProfile {
+	if os.Getenv("NO_COLOR") != "" {
+		return ColorProfileMonochrome
+	}
+
+	if os.Getenv("TERM") == "dumb" {
+		return ColorProfileMonochrome
+	}
+
+	colorTerm := strings.ToLower(os.Getenv("COLORTERM"))
+	if colorTerm == "truecolor" || colorTerm == "24bit" {
+		return ColorProfileTrueColor
+	}
+
+	term := strings.ToLower(os.Getenv("TERM"))
+	if strings.Contains(term, "truecolor") || strings.Contains(term, "24bit") || strings.Contains(term, "direct") {
+		return ColorProfileTrueColor
+	}
+
+	if strings.Contains(term, "256color") {
+		return ColorProfile256
+	}
+
+	return ColorProfileBasic
 }
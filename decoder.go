@@ -0,0 +1,330 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+)
+
+const escape = '\033'
+
+// EscapeTimeout is how long to wait for the rest of an escape sequence once the escape byte has been read. If nothing
+// else arrives in time then the Escape key was pressed on its own. Increase it for connections where escape sequences
+// can be split up with a long delay, such as SSH over a slow network.
+var EscapeTimeout = 50 * time.Millisecond
+
+// keyDecoder decodes the key presses sent by a terminal in raw mode, which are UTF-8 text, control characters, and CSI
+// and SS3 escape sequences. Decoding happens in the background so that the keys can be waited for along with a context.
+type keyDecoder struct {
+	keys chan keyResult
+	done chan struct{}
+
+	// The error that stopped the decoder, once it has been received
+	err error
+}
+
+type keyResult struct {
+	key Key
+	err error
+}
+
+// newKeyDecoder starts decoding the keys read from in. The decoder stops once reading returns an error, which is sent
+// after the keys that were read before it.
+func newKeyDecoder(in io.Reader) *keyDecoder {
+	d := &keyDecoder{
+		keys: make(chan keyResult),
+		done: make(chan struct{}),
+	}
+
+	reads := make(chan []byte)
+	readErr := make(chan error, 1)
+	go func() {
+		for {
+			buffer := make([]byte, 256)
+			n, err := in.Read(buffer)
+			if n > 0 {
+				select {
+				case reads <- buffer[:n]:
+				case <-d.done:
+					return
+				}
+			}
+
+			if err != nil {
+				readErr <- err
+				close(reads)
+				return
+			}
+		}
+	}()
+
+	go d.decode(reads, readErr)
+	return d
+}
+
+// errDecoderClosed is returned when the decoder is closed while a key is being waited for.
+var errDecoderClosed = errors.New("the terminal was closed")
+
+// readKey waits for the next key. It returns ctx.Err() if the context is done first, and a key that arrives after that
+// is returned by the next call.
+func (d *keyDecoder) readKey(ctx context.Context) (Key, error) {
+	if d.err != nil {
+		return nil, d.err
+	}
+
+	select {
+	case result := <-d.keys:
+		if result.err != nil {
+			d.err = fmt.Errorf("error getting key input: %w", result.err)
+			return nil, d.err
+		}
+
+		return result.key, nil
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	case <-d.done:
+		return nil, errDecoderClosed
+	}
+}
+
+// close stops the decoder from sending any more keys. The reader isn't stopped, so it should be closed as well to end
+// a read that is in progress.
+func (d *keyDecoder) close() {
+	close(d.done)
+}
+
+// decode turns the bytes that are read into keys. An incomplete escape sequence is held back until the rest of it is
+// read or the escape timeout passes.
+func (d *keyDecoder) decode(reads <-chan []byte, readErr <-chan error) {
+	var pending []byte
+	var timeout <-chan time.Time
+	for {
+		isFinal := false
+		select {
+		case read, ok := <-reads:
+			if !ok {
+				d.send(pending, true)
+				d.sendResult(keyResult{err: <-readErr})
+				return
+			}
+
+			pending = append(pending, read...)
+		case <-timeout:
+			isFinal = true
+		case <-d.done:
+			return
+		}
+
+		var ok bool
+		pending, ok = d.send(pending, isFinal)
+		if !ok {
+			return
+		}
+
+		timeout = nil
+		if len(pending) > 0 {
+			timeout = time.After(EscapeTimeout)
+		}
+	}
+}
+
+// send sends every key that can be decoded from the input and returns the bytes that are left over. When isFinal is
+// true, an incomplete key is decoded as well as it can be. It returns false if the decoder was closed.
+func (d *keyDecoder) send(input []byte, isFinal bool) ([]byte, bool) {
+	for len(input) > 0 {
+		key, n, ok := decodeKey(input, isFinal)
+		if n == 0 {
+			break
+		}
+
+		input = input[n:]
+		if !ok {
+			continue
+		}
+
+		if !d.sendResult(keyResult{key: key}) {
+			return nil, false
+		}
+	}
+
+	return input, true
+}
+
+func (d *keyDecoder) sendResult(result keyResult) bool {
+	select {
+	case d.keys <- result:
+		return true
+	case <-d.done:
+		return false
+	}
+}
+
+// decodeKey decodes the key at the start of the input and returns the number of bytes that it took up. The number is
+// zero when more input is needed, which can only happen when isFinal is false. It returns false for sequences that
+// aren't recognized, which should be skipped.
+func decodeKey(input []byte, isFinal bool) (Key, int, bool) {
+	if input[0] != escape {
+		if !utf8.FullRune(input) && !isFinal {
+			return nil, 0, false
+		}
+
+		r, size := utf8.DecodeRune(input)
+		return runeToKey(r), size, true
+	}
+
+	if len(input) == 1 {
+		if !isFinal {
+			return nil, 0, false
+		}
+
+		return ControlEscape, 1, true
+	}
+
+	if input[1] != '[' && input[1] != 'O' {
+		// Any other rune following an escape byte was typed while holding Alt.
+		rest := input[1:]
+		if !utf8.FullRune(rest) && !isFinal {
+			return nil, 0, false
+		}
+
+		r, size := utf8.DecodeRune(rest)
+		return ModifiedKey{Key: runeToKey(r), Modifiers: ModAlt}, 1 + size, true
+	}
+
+	// Parameter and intermediate bytes come before the final byte.
+	for i := 2; i < len(input); i++ {
+		if input[i] >= 0x40 && input[i] <= 0x7E {
+			key, ok := escapeSequenceKey(string(input[2:i]), input[i])
+			return key, i + 1, ok
+		}
+
+		if input[i] < 0x20 || input[i] > 0x3F {
+			// The sequence was cut short, so the escape was pressed on its own.
+			return ControlEscape, 1, true
+		}
+	}
+
+	if !isFinal {
+		return nil, 0, false
+	}
+
+	return ControlEscape, 1, true
+}
+
+// The keys sent by control characters, by their value
+var controlCharacterKeys = [...]ControlKey{
+	0x01: ControlCtrlA,
+	0x02: ControlCtrlB,
+	0x03: ControlCtrlC,
+	0x04: ControlCtrlD,
+	0x05: ControlCtrlE,
+	0x06: ControlCtrlF,
+	0x07: ControlCtrlG,
+	0x08: ControlBackspace,
+	0x09: ControlTab,
+	0x0A: ControlCtrlJ,
+	0x0B: ControlCtrlK,
+	0x0C: ControlCtrlL,
+	0x0D: ControlEnter,
+	0x0E: ControlCtrlN,
+	0x0F: ControlCtrlO,
+	0x10: ControlCtrlP,
+	0x11: ControlCtrlQ,
+	0x12: ControlCtrlR,
+	0x13: ControlCtrlS,
+	0x14: ControlCtrlT,
+	0x15: ControlCtrlU,
+	0x16: ControlCtrlV,
+	0x17: ControlCtrlW,
+	0x18: ControlCtrlX,
+	0x19: ControlCtrlY,
+	0x1A: ControlCtrlZ,
+	0x1B: ControlEscape,
+	0x20: ControlSpace,
+}
+
+// runeToKey converts a rune that was read on its own into a key, treating control characters as the keys that send
+// them.
+func runeToKey(r rune) Key {
+	if r >= 0 && int(r) < len(controlCharacterKeys) {
+		return controlCharacterKeys[r]
+	}
+
+	if r == 0x7F {
+		return ControlBackspace
+	}
+
+	return RuneKey(r)
+}
+
+// The keys sent by CSI and SS3 sequences that end with a letter
+var escapeSequenceLetterKeys = map[byte]ControlKey{
+	'A': ControlUp,
+	'B': ControlDown,
+	'C': ControlRight,
+	'D': ControlLeft,
+	'H': ControlHome,
+	'F': ControlEnd,
+	'P': ControlF1,
+	'Q': ControlF2,
+	'R': ControlF3,
+	'S': ControlF4,
+}
+
+// The keys sent by CSI sequences that end with a tilde, by their first parameter
+var escapeSequenceTildeKeys = map[string]ControlKey{
+	"1":  ControlHome,
+	"2":  ControlInsert,
+	"3":  ControlDelete,
+	"4":  ControlEnd,
+	"5":  ControlPageUp,
+	"6":  ControlPageDown,
+	"7":  ControlHome,
+	"8":  ControlEnd,
+	"11": ControlF1,
+	"12": ControlF2,
+	"13": ControlF3,
+	"14": ControlF4,
+	"15": ControlF5,
+	"17": ControlF6,
+	"18": ControlF7,
+	"19": ControlF8,
+	"20": ControlF9,
+	"21": ControlF10,
+	"23": ControlF11,
+	"24": ControlF12,
+}
+
+// escapeSequenceKey returns the key sent by an escape sequence with the given parameters and final byte. Modifiers are
+// sent in the second parameter as one more than the sum of 1 for Shift, 2 for Alt, 4 for Ctrl and 8 for Meta, such as
+// "1;5" for Ctrl.
+func escapeSequenceKey(params string, final byte) (Key, bool) {
+	code, modifierParam, _ := strings.Cut(params, ";")
+
+	var modifiers Modifiers
+	if modifierParam != "" {
+		n, err := strconv.Atoi(modifierParam)
+		if err != nil || n < 1 {
+			return nil, false
+		}
+
+		modifiers = Modifiers(n - 1)
+	}
+
+	if final == 'Z' {
+		return ModifiedKey{Key: ControlTab, Modifiers: modifiers | ModShift}, true
+	}
+
+	if final == '~' {
+		key, ok := escapeSequenceTildeKeys[code]
+		return withModifiers(key, modifiers), ok
+	}
+
+	key, ok := escapeSequenceLetterKeys[final]
+	return withModifiers(key, modifiers), ok
+}
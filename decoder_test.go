@@ -0,0 +1,219 @@
+package prompt
+
+import (
+	"context"
+	"errors"
+	"io"
+	"testing"
+	"time"
+	"unicode/utf8"
+)
+
+func TestDecodeKey(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		isFinal     bool
+		expected    Key
+		expectedLen int
+		isSkipped   bool
+	}{
+		{name: "rune", input: "ab", expected: RuneKey('a'), expectedLen: 1},
+		{name: "multibyte rune", input: "日本", expected: RuneKey('日'), expectedLen: 3},
+		{name: "incomplete rune", input: "\xe6\x97", expectedLen: 0},
+		{name: "incomplete rune at the end of the input", input: "\xe6\x97", isFinal: true, expected: RuneKey(utf8.RuneError), expectedLen: 1},
+		{name: "control character", input: "\x03", expected: ControlCtrlC, expectedLen: 1},
+		{name: "carriage return", input: "\r", expected: ControlEnter, expectedLen: 1},
+		{name: "delete character", input: "\x7f", expected: ControlBackspace, expectedLen: 1},
+		{name: "space", input: " ", expected: ControlSpace, expectedLen: 1},
+		{name: "escape waiting for more", input: "\x1b", expectedLen: 0},
+		{name: "escape on its own", input: "\x1b", isFinal: true, expected: ControlEscape, expectedLen: 1},
+		{name: "alt rune", input: "\x1bb", expected: ModifiedKey{Key: RuneKey('b'), Modifiers: ModAlt}, expectedLen: 2},
+		{name: "alt multibyte rune", input: "\x1b日", expected: ModifiedKey{Key: RuneKey('日'), Modifiers: ModAlt}, expectedLen: 4},
+		{name: "alt incomplete rune", input: "\x1b\xe6", expectedLen: 0},
+		{name: "alt control character", input: "\x1b\x7f", expected: ModifiedKey{Key: ControlBackspace, Modifiers: ModAlt}, expectedLen: 2},
+		{name: "arrow", input: "\x1b[A", expected: ControlUp, expectedLen: 3},
+		{name: "arrow followed by text", input: "\x1b[Dab", expected: ControlLeft, expectedLen: 3},
+		{name: "application mode arrow", input: "\x1bOB", expected: ControlDown, expectedLen: 3},
+		{name: "incomplete sequence", input: "\x1b[1;5", expectedLen: 0},
+		{name: "incomplete sequence at the end of the input", input: "\x1b[1;5", isFinal: true, expected: ControlEscape, expectedLen: 1},
+		{name: "sequence cut short", input: "\x1b[1\x03", expected: ControlEscape, expectedLen: 1},
+		{name: "ctrl arrow", input: "\x1b[1;5C", expected: ModifiedKey{Key: ControlRight, Modifiers: ModCtrl}, expectedLen: 6},
+		{name: "shift arrow", input: "\x1b[1;2A", expected: ModifiedKey{Key: ControlUp, Modifiers: ModShift}, expectedLen: 6},
+		{name: "ctrl alt arrow", input: "\x1b[1;7D", expected: ModifiedKey{Key: ControlLeft, Modifiers: ModCtrl | ModAlt}, expectedLen: 6},
+		{name: "meta arrow", input: "\x1b[1;9B", expected: ModifiedKey{Key: ControlDown, Modifiers: ModMeta}, expectedLen: 6},
+		{name: "ctrl delete", input: "\x1b[3;5~", expected: ModifiedKey{Key: ControlDelete, Modifiers: ModCtrl}, expectedLen: 6},
+		{name: "shift tab", input: "\x1b[Z", expected: ModifiedKey{Key: ControlTab, Modifiers: ModShift}, expectedLen: 3},
+		{name: "home", input: "\x1b[H", expected: ControlHome, expectedLen: 3},
+		{name: "end with tilde", input: "\x1b[4~", expected: ControlEnd, expectedLen: 4},
+		{name: "insert", input: "\x1b[2~", expected: ControlInsert, expectedLen: 4},
+		{name: "delete", input: "\x1b[3~", expected: ControlDelete, expectedLen: 4},
+		{name: "page up", input: "\x1b[5~", expected: ControlPageUp, expectedLen: 4},
+		{name: "page down", input: "\x1b[6~", expected: ControlPageDown, expectedLen: 4},
+		{name: "SS3 F1", input: "\x1bOP", expected: ControlF1, expectedLen: 3},
+		{name: "SS3 F2", input: "\x1bOQ", expected: ControlF2, expectedLen: 3},
+		{name: "SS3 F3", input: "\x1bOR", expected: ControlF3, expectedLen: 3},
+		{name: "SS3 F4", input: "\x1bOS", expected: ControlF4, expectedLen: 3},
+		{name: "CSI F1", input: "\x1b[11~", expected: ControlF1, expectedLen: 5},
+		{name: "F5", input: "\x1b[15~", expected: ControlF5, expectedLen: 5},
+		{name: "F12", input: "\x1b[24~", expected: ControlF12, expectedLen: 5},
+		{name: "shift F5", input: "\x1b[15;2~", expected: ModifiedKey{Key: ControlF5, Modifiers: ModShift}, expectedLen: 7},
+		{name: "ctrl F1", input: "\x1b[1;5P", expected: ModifiedKey{Key: ControlF1, Modifiers: ModCtrl}, expectedLen: 6},
+		{name: "unknown tilde sequence", input: "\x1b[200~a", expectedLen: 6, isSkipped: true},
+		{name: "unknown final byte", input: "\x1b[5n", expectedLen: 4, isSkipped: true},
+		{name: "invalid modifier", input: "\x1b[1;0A", expectedLen: 6, isSkipped: true},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			key, n, ok := decodeKey([]byte(test.input), test.isFinal)
+			if n != test.expectedLen {
+				t.Fatalf("expected to decode %d bytes but decoded %d", test.expectedLen, n)
+			}
+
+			if n == 0 {
+				return
+			}
+
+			if ok == test.isSkipped {
+				t.Fatalf("expected the sequence to be skipped to be %t", test.isSkipped)
+			}
+
+			if !test.isSkipped && key != test.expected {
+				t.Errorf("expected key %#v but got %#v", test.expected, key)
+			}
+		})
+	}
+}
+
+func TestKeyDecoder(t *testing.T) {
+	// Give the pieces of a split escape sequence plenty of time to arrive.
+	defer func(timeout time.Duration) { EscapeTimeout = timeout }(EscapeTimeout)
+	EscapeTimeout = time.Second
+
+	tests := []struct {
+		name     string
+		reads    []string
+		expected []Key
+	}{
+		{
+			name:     "rune split across reads",
+			reads:    []string{"a\xe6", "\x97", "\xa5b"},
+			expected: []Key{RuneKey('a'), RuneKey('日'), RuneKey('b')},
+		},
+		{
+			name:     "escape sequence split across reads",
+			reads:    []string{"\x1b", "[1;", "5C"},
+			expected: []Key{ModifiedKey{Key: ControlRight, Modifiers: ModCtrl}},
+		},
+		{
+			name:     "several keys in one read",
+			reads:    []string{"\x1b[A\x1b[Bx\r"},
+			expected: []Key{ControlUp, ControlDown, RuneKey('x'), ControlEnter},
+		},
+		{
+			name:     "unknown sequences are skipped",
+			reads:    []string{"a\x1b[200~b\x1b[5n", "c"},
+			expected: []Key{RuneKey('a'), RuneKey('b'), RuneKey('c')},
+		},
+		{
+			name:     "escape at the end of the input",
+			reads:    []string{"a\x1b"},
+			expected: []Key{RuneKey('a'), ControlEscape},
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			reader, writer := io.Pipe()
+			decoder := newKeyDecoder(reader)
+			defer decoder.close()
+
+			go func() {
+				for _, read := range test.reads {
+					writer.Write([]byte(read))
+				}
+				writer.Close()
+			}()
+
+			for _, expected := range test.expected {
+				key, err := decoder.readKey(context.Background())
+				if err != nil {
+					t.Fatal(err)
+				}
+
+				if key != expected {
+					t.Fatalf("expected key %#v but got %#v", expected, key)
+				}
+			}
+
+			_, err := decoder.readKey(context.Background())
+			if !errors.Is(err, io.EOF) {
+				t.Errorf("expected the end of the input but got %v", err)
+			}
+		})
+	}
+}
+
+func TestKeyDecoderEscapeTimeout(t *testing.T) {
+	defer func(timeout time.Duration) { EscapeTimeout = timeout }(EscapeTimeout)
+	EscapeTimeout = 10 * time.Millisecond
+
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	decoder := newKeyDecoder(reader)
+	defer decoder.close()
+
+	// The escape is pressed on its own, so the bytes after the timeout are ordinary keys.
+	writer.Write([]byte("\x1b"))
+
+	key, err := decoder.readKey(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key != ControlEscape {
+		t.Fatalf("expected the Escape key but got %#v", key)
+	}
+
+	go writer.Write([]byte("[A"))
+	for _, expected := range []Key{RuneKey('['), RuneKey('A')} {
+		key, err := decoder.readKey(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		if key != expected {
+			t.Fatalf("expected key %#v but got %#v", expected, key)
+		}
+	}
+}
+
+func TestKeyDecoderReadKeyContext(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	decoder := newKeyDecoder(reader)
+	defer decoder.close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 10*time.Millisecond)
+	defer cancel()
+
+	_, err := decoder.readKey(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded but got %v", err)
+	}
+
+	// A key that arrives after the context is done is kept for the next read.
+	go writer.Write([]byte("a"))
+
+	key, err := decoder.readKey(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if key != RuneKey('a') {
+		t.Errorf("expected key 'a' but got %#v", key)
+	}
+}
@@ -0,0 +1,40 @@
+package prompt
+
+import (
+	"errors"
+	"fmt"
+)
+
+var (
+	// ErrInterrupted is returned when the user presses Ctrl-C while a prompt is shown.
+	ErrInterrupted = errors.New("prompt was interrupted")
+
+	// ErrEOF is returned when the user presses Ctrl-D while the input is empty, or when the input ends before a
+	// response is read without the interactive prompt.
+	ErrEOF = errors.New("end of input")
+
+	// ErrInvalidState is returned when a prompt is shown, paused or reset while it's in a state that doesn't allow it.
+	ErrInvalidState = errors.New("invalid prompt state")
+)
+
+// TerminalError is returned when the terminal can't be set up for a prompt or can't be restored afterwards.
+type TerminalError struct {
+	// What was being done with the terminal, such as "open" or "close"
+	Op string
+
+	// The error returned by the terminal
+	Err error
+}
+
+func (t *TerminalError) Error() string {
+	return fmt.Sprintf("couldn't %s the terminal: %v", t.Op, t.Err)
+}
+
+func (t *TerminalError) Unwrap() error {
+	return t.Err
+}
+
+// stateError describes why a prompt's state doesn't allow what was asked of it.
+func stateError(message string) error {
+	return fmt.Errorf("%w: %s", ErrInvalidState, message)
+}
@@ -0,0 +1,330 @@
+package prompt
+
+import (
+	"strings"
+	"unicode"
+)
+
+// FilterMode selects how the text typed into a Select or MultiSelect is matched against the options.
+type FilterMode int
+
+const (
+	// FilterContains matches options that contain the filter, ignoring case. Options stay in their original order.
+	FilterContains FilterMode = iota
+
+	// FilterFuzzy matches options that contain every rune of the filter in order, ignoring case. Options are ranked so
+	// that the closest matches, such as ones where the runes are consecutive or start words, are listed first.
+	FilterFuzzy
+)
+
+// FilterMatch describes how an option matched the filter.
+type FilterMatch struct {
+	// Options with higher scores are listed first
+	Score int
+
+	// The indexes of the runes in the option's name that matched the filter. They are highlighted in the list.
+	NamePositions []int
+
+	// The indexes of the runes in the option's description, without any markup, that matched the filter. They are
+	// highlighted in the list.
+	DescriptionPositions []int
+}
+
+// FilterFunc matches an option against the filter typed by the user. It returns false when the option doesn't match.
+type FilterFunc func(filter string, option SelectionOption) (FilterMatch, bool)
+
+// matchFunc matches a lower case pattern against some text. Positions are indexes of runes in the text.
+type matchFunc func(pattern []rune, text string) (score int, positions []int, ok bool)
+
+// optionMatcher matches options against the filter typed by the user.
+type optionMatcher struct {
+	match FilterFunc
+
+	// Whether the options that match are listed with the highest score first
+	isRanked bool
+
+	// Whether adding to the filter can only remove matches. When it can, only the options that matched the shorter
+	// filter have to be checked again.
+	isNarrowing bool
+}
+
+// newOptionMatcher returns the matcher for the prompt's filter settings.
+func newOptionMatcher(mode FilterMode, filterFunc FilterFunc, shouldFilterDescription, shouldFilterID bool) optionMatcher {
+	if filterFunc != nil {
+		return optionMatcher{match: filterFunc, isRanked: true}
+	}
+
+	match := containsMatch
+	if mode == FilterFuzzy {
+		match = fuzzyMatch
+	}
+
+	return optionMatcher{
+		match:       newFilterFunc(match, shouldFilterDescription, shouldFilterID),
+		isRanked:    mode == FilterFuzzy,
+		isNarrowing: true,
+	}
+}
+
+// newFilterFunc creates a FilterFunc that matches the name of an option, and optionally its description and ID. The
+// field with the best score is used.
+func newFilterFunc(match matchFunc, shouldFilterDescription, shouldFilterID bool) FilterFunc {
+	// The filter is the same for every option, so it is only lowered when it changes.
+	var lastFilter string
+	var pattern []rune
+
+	return func(filter string, option SelectionOption) (FilterMatch, bool) {
+		if pattern == nil || filter != lastFilter {
+			lastFilter, pattern = filter, toLowerRunes(filter)
+		}
+
+		result, isMatch := FilterMatch{}, false
+		if score, positions, ok := match(pattern, option.Name); ok {
+			result, isMatch = FilterMatch{Score: score, NamePositions: positions}, true
+		}
+
+		if shouldFilterDescription {
+			score, positions, ok := match(pattern, stripMarkup(option.Description))
+			if ok && (!isMatch || score > result.Score) {
+				result, isMatch = FilterMatch{Score: score, DescriptionPositions: positions}, true
+			}
+		}
+
+		if shouldFilterID {
+			score, _, ok := match(pattern, option.ID)
+			if ok && (!isMatch || score > result.Score) {
+				result, isMatch = FilterMatch{Score: score}, true
+			}
+		}
+
+		return result, isMatch
+	}
+}
+
+// containsMatch finds the first place that the pattern appears in the text, ignoring case. Every match has the same
+// score.
+func containsMatch(pattern []rune, text string) (int, []int, bool) {
+	start := 0
+	for offset := range text {
+		if hasPrefixIgnoringCase(text[offset:], pattern) {
+			positions := make([]int, len(pattern))
+			for i := range positions {
+				positions[i] = start + i
+			}
+
+			return 0, positions, true
+		}
+
+		start++
+	}
+
+	return 0, nil, false
+}
+
+func hasPrefixIgnoringCase(text string, pattern []rune) bool {
+	i := 0
+	for _, r := range text {
+		if i == len(pattern) {
+			return true
+		}
+
+		if unicode.ToLower(r) != pattern[i] {
+			return false
+		}
+
+		i++
+	}
+
+	return i == len(pattern)
+}
+
+// Scores used to rank fuzzy matches. They follow the scheme used by fzf, where matched runes earn points, gaps between
+// them cost points and runes at the start of words earn bonuses.
+const (
+	fuzzyScoreMatch        = 16
+	fuzzyScoreGapStart     = -3
+	fuzzyScoreGapExtension = -1
+
+	fuzzyBonusBoundary    = fuzzyScoreMatch / 2
+	fuzzyBonusNonWord     = fuzzyScoreMatch / 2
+	fuzzyBonusCamelCase   = fuzzyBonusBoundary - 1
+	fuzzyBonusConsecutive = -(fuzzyScoreGapStart + fuzzyScoreGapExtension)
+
+	fuzzyBonusFirstRuneMultiplier = 2
+)
+
+type charClass int
+
+const (
+	charNonWord charClass = iota
+	charLower
+	charUpper
+	charLetter
+	charDigit
+)
+
+// fuzzyMatch finds the shortest part of the text that contains the runes of the pattern in order, ignoring case, and
+// scores it.
+func fuzzyMatch(pattern []rune, text string) (int, []int, bool) {
+	if len(pattern) == 0 {
+		return 0, nil, true
+	}
+
+	// Most options don't match, so check before converting the text.
+	if !isSubsequenceIgnoringCase(text, pattern) {
+		return 0, nil, false
+	}
+
+	return fuzzyMatchRunes(pattern, []rune(text))
+}
+
+func isSubsequenceIgnoringCase(text string, pattern []rune) bool {
+	i := 0
+	for _, r := range text {
+		if unicode.ToLower(r) == pattern[i] {
+			i++
+			if i == len(pattern) {
+				return true
+			}
+		}
+	}
+
+	return false
+}
+
+// fuzzyMatchRunes finds the shortest part of the text that contains the runes of the pattern in order and scores it.
+func fuzzyMatchRunes(pattern, text []rune) (int, []int, bool) {
+	// Find where the earliest match ends.
+	end := -1
+	patternIndex := 0
+	for i, r := range text {
+		if unicode.ToLower(r) != pattern[patternIndex] {
+			continue
+		}
+
+		patternIndex++
+		if patternIndex == len(pattern) {
+			end = i + 1
+			break
+		}
+	}
+
+	if end == -1 {
+		return 0, nil, false
+	}
+
+	// Work backwards from there to find the latest start, which gives the shortest match.
+	start := 0
+	patternIndex = len(pattern) - 1
+	for i := end - 1; i >= 0; i-- {
+		if unicode.ToLower(text[i]) != pattern[patternIndex] {
+			continue
+		}
+
+		patternIndex--
+		if patternIndex < 0 {
+			start = i
+			break
+		}
+	}
+
+	return scoreFuzzyMatch(pattern, text, start, end)
+}
+
+// scoreFuzzyMatch scores the match of the pattern within text[start:end] and returns the positions of the matched
+// runes.
+func scoreFuzzyMatch(pattern, text []rune, start, end int) (int, []int, bool) {
+	score := 0
+	positions := make([]int, 0, len(pattern))
+
+	previousClass := charNonWord
+	if start > 0 {
+		previousClass = classOf(text[start-1])
+	}
+
+	patternIndex := 0
+	isInGap := false
+	numConsecutive := 0
+	firstBonus := 0
+	for i := start; i < end && patternIndex < len(pattern); i++ {
+		class := classOf(text[i])
+
+		if unicode.ToLower(text[i]) == pattern[patternIndex] {
+			positions = append(positions, i)
+			score += fuzzyScoreMatch
+
+			bonus := fuzzyBonus(previousClass, class)
+			if numConsecutive == 0 {
+				firstBonus = bonus
+			} else {
+				// A run of consecutive runes keeps the bonus of the rune that started it.
+				if bonus >= fuzzyBonusBoundary && bonus > firstBonus {
+					firstBonus = bonus
+				}
+
+				bonus = max(max(bonus, firstBonus), fuzzyBonusConsecutive)
+			}
+
+			if patternIndex == 0 {
+				score += bonus * fuzzyBonusFirstRuneMultiplier
+			} else {
+				score += bonus
+			}
+
+			isInGap = false
+			numConsecutive++
+			patternIndex++
+		} else {
+			if isInGap {
+				score += fuzzyScoreGapExtension
+			} else {
+				score += fuzzyScoreGapStart
+			}
+
+			isInGap = true
+			numConsecutive = 0
+			firstBonus = 0
+		}
+
+		previousClass = class
+	}
+
+	return score, positions, true
+}
+
+// fuzzyBonus returns the bonus for matching a rune of the given class that follows a rune of the previous class.
+func fuzzyBonus(previousClass, class charClass) int {
+	if class == charNonWord {
+		return fuzzyBonusNonWord
+	}
+
+	if previousClass == charNonWord {
+		return fuzzyBonusBoundary
+	}
+
+	if previousClass == charLower && class == charUpper || previousClass != charDigit && class == charDigit {
+		return fuzzyBonusCamelCase
+	}
+
+	return 0
+}
+
+func classOf(r rune) charClass {
+	switch {
+	case unicode.IsLower(r):
+		return charLower
+	case unicode.IsUpper(r):
+		return charUpper
+	case unicode.IsLetter(r):
+		return charLetter
+	case unicode.IsDigit(r):
+		return charDigit
+	default:
+		return charNonWord
+	}
+}
+
+// toLowerRunes lowers each rune of the text individually so that indexes line up with the original text.
+func toLowerRunes(text string) []rune {
+	return []rune(strings.Map(unicode.ToLower, text))
+}
@@ -4,17 +4,15 @@ go 1.22.3
 
 require (
 	github.com/JosephNaberhaus/texteditor v1.0.0
-	github.com/eiannone/keyboard v0.0.0-20220611211555-0d226195f203
 	github.com/rivo/uniseg v0.4.7
 	github.com/snugfox/ansi-escapes v0.2.1-0.20201222033053-82a0109803f0
+	golang.org/x/sys v0.30.0
+	golang.org/x/term v0.29.0
+	gopkg.in/yaml.v3 v3.0.1
 )
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	github.com/stretchr/testify v1.9.0 // indirect
-	golang.org/x/sys v0.0.0-20220520151302-bc2c85ada10a // indirect
-	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
-
-replace github.com/eiannone/keyboard => github.com/cszczepaniak/keyboard v0.1.0
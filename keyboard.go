@@ -1,9 +1,6 @@
 package prompt
 
-import (
-	editor "github.com/JosephNaberhaus/texteditor"
-	"github.com/eiannone/keyboard"
-)
+import editor "github.com/JosephNaberhaus/texteditor"
 
 type Key interface {
 	IsText() bool
@@ -73,85 +70,62 @@ const (
 	ControlCtrlX
 	ControlCtrlY
 	ControlCtrlZ
+	ControlEscape
+	ControlDelete
+	ControlInsert
+	ControlPageUp
+	ControlPageDown
+	ControlF1
+	ControlF2
+	ControlF3
+	ControlF4
+	ControlF5
+	ControlF6
+	ControlF7
+	ControlF8
+	ControlF9
+	ControlF10
+	ControlF11
+	ControlF12
 )
 
-func ToKey(rune rune, key keyboard.Key) Key {
-	if rune != 0 {
-		return RuneKey(rune)
-	}
+// ControlTab is the Tab key. Terminals send the same byte for Tab and Ctrl-I, so they can't be told apart. Shift-Tab is
+// a ModifiedKey of ControlTab with ModShift.
+const ControlTab = ControlCtrlI
+
+// Modifiers is the set of modifier keys that were held down along with a key.
+type Modifiers uint8
+
+const (
+	ModShift Modifiers = 1 << iota
+	ModAlt
+	ModCtrl
+	ModMeta
+)
+
+// ModifiedKey is a key that was pressed along with modifier keys, such as Ctrl-Right or Alt-B. Keys that are pressed
+// without modifiers are never wrapped, and neither are the Ctrl keys that have their own ControlKey.
+type ModifiedKey struct {
+	Key       Key
+	Modifiers Modifiers
+}
+
+// IsText is always false, since a key pressed with modifiers is a shortcut rather than text to type.
+func (m ModifiedKey) IsText() bool {
+	return false
+}
 
-	switch key {
-	case keyboard.KeyArrowLeft:
-		return ControlLeft
-	case keyboard.KeyArrowRight:
-		return ControlRight
-	case keyboard.KeyArrowUp:
-		return ControlUp
-	case keyboard.KeyArrowDown:
-		return ControlDown
-	case keyboard.KeyEnter:
-		return ControlEnter
-	case keyboard.KeyBackspace:
-		fallthrough
-	case keyboard.KeyBackspace2:
-		return ControlBackspace
-	case keyboard.KeySpace:
-		return ControlSpace
-	case keyboard.KeyHome:
-		return ControlHome
-	case keyboard.KeyEnd:
-		return ControlEnd
-	case keyboard.KeyCtrlA:
-		return ControlCtrlA
-	case keyboard.KeyCtrlB:
-		return ControlCtrlB
-	case keyboard.KeyCtrlC:
-		return ControlCtrlC
-	case keyboard.KeyCtrlD:
-		return ControlCtrlD
-	case keyboard.KeyCtrlE:
-		return ControlCtrlE
-	case keyboard.KeyCtrlF:
-		return ControlCtrlF
-	case keyboard.KeyCtrlG:
-		return ControlCtrlG
-	case keyboard.KeyCtrlI:
-		return ControlCtrlI
-	case keyboard.KeyCtrlJ:
-		return ControlCtrlJ
-	case keyboard.KeyCtrlK:
-		return ControlCtrlK
-	case keyboard.KeyCtrlL:
-		return ControlCtrlL
-	case keyboard.KeyCtrlN:
-		return ControlCtrlN
-	case keyboard.KeyCtrlO:
-		return ControlCtrlO
-	case keyboard.KeyCtrlP:
-		return ControlCtrlP
-	case keyboard.KeyCtrlQ:
-		return ControlCtrlQ
-	case keyboard.KeyCtrlR:
-		return ControlCtrlR
-	case keyboard.KeyCtrlS:
-		return ControlCtrlS
-	case keyboard.KeyCtrlT:
-		return ControlCtrlT
-	case keyboard.KeyCtrlU:
-		return ControlCtrlU
-	case keyboard.KeyCtrlV:
-		return ControlCtrlV
-	case keyboard.KeyCtrlW:
-		return ControlCtrlW
-	case keyboard.KeyCtrlX:
-		return ControlCtrlX
-	case keyboard.KeyCtrlY:
-		return ControlCtrlY
-	case keyboard.KeyCtrlZ:
-		return ControlCtrlZ
-	default:
-		return Noop
+func (m ModifiedKey) Rune() rune {
+	return m.Key.Rune()
+}
+
+// withModifiers wraps the key in a ModifiedKey when any modifiers were held down.
+func withModifiers(key Key, modifiers Modifiers) Key {
+	if modifiers == 0 {
+		return key
 	}
+
+	return ModifiedKey{Key: key, Modifiers: modifiers}
 }
 
 func applyKeyToEditor(k Key, editor *editor.TextEditor) {
@@ -172,6 +146,12 @@ func applyKeyToEditor(k Key, editor *editor.TextEditor) {
 		editor.Newline()
 	case ControlBackspace:
 		editor.Backspace()
+	case ControlDelete:
+		// Deleting forwards is a backspace from after the next grapheme, or the next newline at the end of a paragraph.
+		if !editor.CursorIsAtEndOfParagraph() || !editor.CursorIsOnLastParagraph() {
+			editor.Right()
+			editor.Backspace()
+		}
 	case ControlHome:
 		editor.Home()
 	case ControlEnd:
@@ -0,0 +1,215 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+)
+
+// LineTerminal is implemented by terminals that might not be able to read individual key presses, such as when stdin
+// is a pipe. When IsInteractive returns false, prompts print their question and read the response as lines of text
+// instead of showing the interactive prompt.
+type LineTerminal interface {
+	Terminal
+
+	// IsInteractive returns whether individual key presses can be read from the terminal.
+	IsInteractive() bool
+
+	// ReadLine returns the next line of input without its line ending. It returns io.EOF once there is no input left,
+	// and ctx.Err() if the context is done before a line is read.
+	ReadLine(ctx context.Context) (string, error)
+}
+
+// ValidationError is returned when a response is read without the interactive prompt and isn't valid. The user can't
+// be asked again in that case.
+type ValidationError struct {
+	// The message describing why the response isn't valid
+	Message string
+}
+
+func (v *ValidationError) Error() string {
+	return "invalid response: " + v.Message
+}
+
+// The width used to lay out prompts that are shown without a terminal size
+const defaultOutputWidth = 80
+
+type lineReader struct {
+	reader *bufio.Reader
+
+	// Receives the line that is being read in the background. It is kept when reading is cancelled so that the line
+	// isn't lost, and is nil when no line is being read.
+	pending chan lineResult
+}
+
+type lineResult struct {
+	line string
+	err  error
+}
+
+// ReadLine reads lines in the background since reading can't be interrupted. A line that arrives after the context is
+// done is returned by the next call.
+func (l *lineReader) ReadLine(ctx context.Context) (string, error) {
+	if l.pending == nil {
+		l.pending = make(chan lineResult, 1)
+		go func(pending chan<- lineResult) {
+			line, err := l.readLine()
+			pending <- lineResult{line: line, err: err}
+		}(l.pending)
+	}
+
+	select {
+	case result := <-l.pending:
+		l.pending = nil
+		return result.line, result.err
+	case <-ctx.Done():
+		return "", ctx.Err()
+	}
+}
+
+func (l *lineReader) readLine() (string, error) {
+	line, err := l.reader.ReadString('\n')
+	if err != nil && (err != io.EOF || line == "") {
+		return "", err
+	}
+
+	line = strings.TrimSuffix(line, "\n")
+	line = strings.TrimSuffix(line, "\r")
+	return line, nil
+}
+
+type lineOnlyTerminal struct {
+	lineReader
+	out io.Writer
+}
+
+// NewLineTerminal creates a Terminal that only reads whole lines from in. Prompts shown on it never render the
+// interactive prompt.
+func NewLineTerminal(in io.Reader, out io.Writer) Terminal {
+	return &lineOnlyTerminal{
+		lineReader: lineReader{reader: bufio.NewReader(in)},
+		out:        out,
+	}
+}
+
+func (l *lineOnlyTerminal) Write(p []byte) (int, error) {
+	return l.out.Write(p)
+}
+
+func (l *lineOnlyTerminal) Open() error {
+	return nil
+}
+
+func (l *lineOnlyTerminal) Close() error {
+	return nil
+}
+
+func (l *lineOnlyTerminal) ReadKey(ctx context.Context) (Key, error) {
+	return nil, errors.New("can't read keys from a line terminal")
+}
+
+func (l *lineOnlyTerminal) Size() (int, int, error) {
+	return 0, 0, errors.New("line terminals don't have a size")
+}
+
+func (l *lineOnlyTerminal) IsInteractive() bool {
+	return false
+}
+
+// asLineTerminal returns the terminal as a LineTerminal if prompts shown on it need to read lines instead of keys.
+func asLineTerminal(terminal Terminal) (LineTerminal, bool) {
+	lineTerminal, ok := terminal.(LineTerminal)
+	if !ok || lineTerminal.IsInteractive() {
+		return nil, false
+	}
+
+	return lineTerminal, true
+}
+
+// readLine writes the question if it hasn't been written yet and reads the next line of the response.
+func (b *base) readLine(ctx context.Context, question string) (string, error) {
+	err := b.writeQuestionLine(question)
+	if err != nil {
+		return "", err
+	}
+
+	line, err := b.lineTerminal.ReadLine(ctx)
+	if err == io.EOF {
+		return "", fmt.Errorf("no response was given: %w (%w)", ErrEOF, err)
+	}
+
+	return line, err
+}
+
+// readAllLines writes the question if it hasn't been written yet and reads every remaining line of the response.
+func (b *base) readAllLines(ctx context.Context, question string) ([]string, error) {
+	err := b.writeQuestionLine(question)
+	if err != nil {
+		return nil, err
+	}
+
+	var lines []string
+	for {
+		line, err := b.lineTerminal.ReadLine(ctx)
+		if err == io.EOF {
+			return lines, nil
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		lines = append(lines, line)
+	}
+}
+
+// writeQuestionLine writes the question before the response is read, so that the output shows which prompt is
+// waiting for input or failed. The response is written after it on the same line.
+func (b *base) writeQuestionLine(question string) error {
+	if b.isQuestionLineWritten {
+		return nil
+	}
+
+	_, err := fmt.Fprintf(b.terminal, "%s%s:", b.theme.QuestionPrefix, stripMarkup(question))
+	b.isQuestionLineWritten = true
+	return err
+}
+
+// writeLines writes the question, unless it was written before the response was read, and the response so that the
+// output reads like a finished prompt.
+func (b *base) writeLines(question, response string) error {
+	err := b.writeQuestionLine(question)
+	if err != nil {
+		return err
+	}
+
+	separator := " "
+	if strings.Contains(response, "\n") {
+		separator = "\n"
+	}
+
+	_, err = fmt.Fprintf(b.terminal, "%s%s\n", separator, response)
+	return err
+}
+
+// finishLines completes a prompt that was shown without the interactive prompt. Errors send the prompt back to
+// waiting, since there is nothing on the screen to tear down, after ending the line that the question was written on.
+func (b *base) finishLines(err error) error {
+	isQuestionLineWritten := b.isQuestionLineWritten
+	b.isQuestionLineWritten = false
+
+	if err != nil {
+		if isQuestionLineWritten {
+			fmt.Fprintln(b.terminal)
+		}
+
+		b.promptState = Waiting
+		return err
+	}
+
+	b.promptState = Finished
+	return nil
+}
@@ -0,0 +1,101 @@
+package prompt_test
+
+import (
+	"context"
+	"errors"
+	"github.com/JosephNaberhaus/prompt"
+	"io"
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestLineModeWritesQuestionBeforeReading(t *testing.T) {
+	tests := []struct {
+		name        string
+		input       string
+		expected    string
+		expectedErr error
+	}{
+		{
+			name:     "response",
+			input:    "Ada\n",
+			expected: "? Name: Ada\n",
+		},
+		{
+			name:        "end of input",
+			input:       "",
+			expected:    "? Name:\n",
+			expectedErr: prompt.ErrEOF,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			output := &strings.Builder{}
+			p := prompt.Text{
+				Question:     "Name",
+				IsSingleLine: true,
+				Terminal:     prompt.NewLineTerminal(strings.NewReader(test.input), output),
+			}
+
+			err := p.Show()
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected error %v but got %v", test.expectedErr, err)
+			}
+
+			if output.String() != test.expected {
+				t.Errorf("expected output %q but got %q", test.expected, output.String())
+			}
+		})
+	}
+}
+
+func TestLineModeInvalidResponse(t *testing.T) {
+	output := &strings.Builder{}
+	p := prompt.Select{
+		Question: "Color",
+		Options:  []prompt.SelectionOption{{Name: "Red"}, {Name: "Blue"}},
+		Terminal: prompt.NewLineTerminal(strings.NewReader("Green\n"), output),
+	}
+
+	err := p.Show()
+
+	var validationErr *prompt.ValidationError
+	if !errors.As(err, &validationErr) {
+		t.Fatalf("expected a validation error but got %v", err)
+	}
+
+	if output.String() != "? Color:\n" {
+		t.Errorf("expected the question to be written but got %q", output.String())
+	}
+}
+
+func TestLineModeContext(t *testing.T) {
+	reader, writer := io.Pipe()
+	defer writer.Close()
+
+	terminal := prompt.NewLineTerminal(reader, io.Discard)
+
+	ctx, cancel := context.WithTimeout(context.Background(), 50*time.Millisecond)
+	defer cancel()
+
+	first := prompt.Text{Question: "First", IsSingleLine: true, Terminal: terminal}
+	err := first.ShowContext(ctx)
+	if !errors.Is(err, context.DeadlineExceeded) {
+		t.Fatalf("expected the deadline to be exceeded but got %v", err)
+	}
+
+	// The line that the cancelled prompt was waiting for goes to the next prompt.
+	go writer.Write([]byte("answer\n"))
+
+	second := prompt.Text{Question: "Second", IsSingleLine: true, Terminal: terminal}
+	err = second.Show()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if second.Response() != "answer" {
+		t.Errorf("expected the response answer but got %q", second.Response())
+	}
+}
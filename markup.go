@@ -0,0 +1,257 @@
+package prompt
+
+import (
+	"strings"
+)
+
+// Questions, descriptions and validation messages can contain inline markup that styles parts of the text. A tag is a
+// list of styles in square brackets and applies until the matching "[/]":
+//
+//	[bold]       [dim]        [italic]      [underline]
+//	[red]        [#ff8700]    [bg=blue]     [link=https://example.com]
+//	[bold red]   Several styles at once
+//	[[           A literal "["
+//
+// Brackets that don't contain a known tag are written as they are, so text such as "[optional]" doesn't need to be
+// escaped.
+
+// textStyle is the style of a piece of text that was written with markup.
+type textStyle struct {
+	style Style
+
+	// The URL that the text links to
+	link string
+}
+
+// merge returns the style with the overlay applied on top of it.
+func (t textStyle) merge(overlay textStyle) textStyle {
+	if overlay.link != "" {
+		t.link = overlay.link
+	}
+
+	t.style = t.style.merge(overlay.style)
+	return t
+}
+
+// markupSpan is a piece of text that is drawn in a single style.
+type markupSpan struct {
+	text  string
+	style textStyle
+}
+
+// parseMarkup splits the markup into spans of text with the tags removed.
+func parseMarkup(markup string) []markupSpan {
+	if !strings.Contains(markup, "[") {
+		return []markupSpan{{text: markup}}
+	}
+
+	var spans []markupSpan
+	var stack []textStyle
+	current := strings.Builder{}
+
+	endSpan := func() {
+		if current.Len() == 0 {
+			return
+		}
+
+		style := textStyle{}
+		for _, tagStyle := range stack {
+			style = style.merge(tagStyle)
+		}
+
+		spans = append(spans, markupSpan{text: current.String(), style: style})
+		current.Reset()
+	}
+
+	for i := 0; i < len(markup); i++ {
+		if markup[i] != '[' {
+			current.WriteByte(markup[i])
+			continue
+		}
+
+		if strings.HasPrefix(markup[i:], "[[") {
+			current.WriteByte('[')
+			i++
+			continue
+		}
+
+		end := strings.IndexByte(markup[i:], ']')
+		if end == -1 {
+			current.WriteByte('[')
+			continue
+		}
+
+		tag := markup[i+1 : i+end]
+		if tag == "/" && len(stack) > 0 {
+			endSpan()
+			stack = stack[:len(stack)-1]
+			i += end
+			continue
+		}
+
+		style, ok := parseTag(tag)
+		if !ok {
+			current.WriteByte('[')
+			continue
+		}
+
+		endSpan()
+		stack = append(stack, style)
+		i += end
+	}
+
+	endSpan()
+	return spans
+}
+
+// parseTag returns the style described by the contents of a tag, or false if it isn't a tag.
+func parseTag(tag string) (textStyle, bool) {
+	fields := strings.Fields(tag)
+	if len(fields) == 0 {
+		return textStyle{}, false
+	}
+
+	style := textStyle{}
+	for _, field := range fields {
+		name, value, hasValue := strings.Cut(field, "=")
+		switch {
+		case hasValue && name == "link":
+			style.link = value
+		case hasValue && name == "bg":
+			c, ok := parseColorName(value)
+			if !ok {
+				return textStyle{}, false
+			}
+
+			style.style.Background = c
+		case field == "bold":
+			style.style.IsBold = true
+		case field == "dim":
+			style.style.IsDim = true
+		case field == "italic":
+			style.style.IsItalic = true
+		case field == "underline":
+			style.style.IsUnderlined = true
+		default:
+			c, ok := parseColorName(field)
+			if !ok {
+				return textStyle{}, false
+			}
+
+			style.style.Foreground = c
+		}
+	}
+
+	return style, true
+}
+
+var colorNames = map[string]Color{
+	"black":   ColorBlack,
+	"red":     ColorRed,
+	"green":   ColorGreen,
+	"yellow":  ColorYellow,
+	"blue":    ColorBlue,
+	"magenta": ColorMagenta,
+	"cyan":    ColorCyan,
+	"white":   ColorWhite,
+}
+
+// parseColorName parses the name of a basic color or a hex color.
+func parseColorName(name string) (Color, bool) {
+	if strings.HasPrefix(name, "#") {
+		c, err := ParseHexColor(name)
+		return c, err == nil
+	}
+
+	c, ok := colorNames[name]
+	return c, ok
+}
+
+// stripMarkup returns the text of the markup without any tags.
+func stripMarkup(markup string) string {
+	if !strings.Contains(markup, "[") {
+		return markup
+	}
+
+	sb := strings.Builder{}
+	for _, span := range parseMarkup(markup) {
+		sb.WriteString(span.text)
+	}
+
+	return sb.String()
+}
+
+// parseMarkupRunes returns the text of the markup without any tags and the style of each of its runes.
+func parseMarkupRunes(markup string) (string, []textStyle) {
+	spans := parseMarkup(markup)
+	if len(spans) == 1 && spans[0].style == (textStyle{}) {
+		return spans[0].text, nil
+	}
+
+	sb := strings.Builder{}
+	var styles []textStyle
+	for _, span := range spans {
+		sb.WriteString(span.text)
+		for range span.text {
+			styles = append(styles, span.style)
+		}
+	}
+
+	return sb.String(), styles
+}
+
+// writeMarkup writes the markup with each span drawn in its style on top of the base style.
+func (o *output) writeMarkup(markup string, base Style) {
+	for _, span := range parseMarkup(markup) {
+		o.writeText(span.text, textStyle{style: base}.merge(span.style))
+	}
+}
+
+// writeText writes the text in the style, linking it to the style's URL if it has one.
+func (o *output) writeText(text string, style textStyle) {
+	if style.link == "" || o.isMonochrome() {
+		o.writeStyle(text, style.style)
+		return
+	}
+
+	// Hyperlinks are written with OSC 8. Terminals that don't support them ignore the sequence.
+	o.writeEscape("\033]8;;" + style.link + "\033\\")
+	o.writeStyle(text, style.style)
+	o.writeEscape("\033]8;;\033\\")
+}
+
+// writeWrappedMarkup writes the markup from the cursor onwards, breaking it between words so that it fits within the
+// width of the output. Lines after the first are indented by the given number of columns.
+func (o *output) writeWrappedMarkup(markup string, base Style, indent int) {
+	o.writeWrappedSpans(parseMarkup(markup), base, indent)
+}
+
+// writeWrapped is like writeWrappedMarkup for text that doesn't contain markup.
+func (o *output) writeWrapped(text string, style Style, indent int) {
+	o.writeWrappedSpans([]markupSpan{{text: text}}, style, indent)
+}
+
+func (o *output) writeWrappedSpans(spans []markupSpan, base Style, indent int) {
+	sb := strings.Builder{}
+	for _, span := range spans {
+		sb.WriteString(span.text)
+	}
+
+	for i, line := range wrapRanges(sb.String(), o.outputWidth-o.cursorColumn, o.outputWidth-indent) {
+		if i > 0 {
+			o.nextLine()
+			o.write(strings.Repeat(" ", indent))
+		}
+
+		// Write the part of each span that is on the line.
+		offset := 0
+		for _, span := range spans {
+			start, end := max(line.start, offset), min(line.end, offset+len(span.text))
+			if start < end {
+				o.writeText(span.text[start-offset:end-offset], textStyle{style: base}.merge(span.style))
+			}
+
+			offset += len(span.text)
+		}
+	}
+}
@@ -0,0 +1,323 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+	"github.com/rivo/uniseg"
+	"strings"
+)
+
+// MultiSelect lets the user pick any number of options. Space toggles the option under the cursor, Ctrl-A selects every
+// option that matches the filter and Ctrl-N deselects them.
+type MultiSelect struct {
+	base
+
+	// A stable identifier for the prompt. When it is set, the AnswerProvider is asked for a response before the prompt
+	// is shown to the user.
+	ID string
+
+	// Supplies responses to skip the prompt. Defaults to DefaultAnswerProvider.
+	AnswerProvider AnswerProvider
+
+	// The question to display to the user
+	Question string
+
+	// Array of options for the user to select from
+	Options []SelectionOption
+
+	// The number of lines that will be shown at a time
+	// Default is 7
+	NumLinesShown int
+
+	// How the text typed by the user is matched against the options
+	// Default is FilterContains
+	FilterMode FilterMode
+
+	// Matches the options against the text typed by the user, listing the ones with the highest score first. Overrides
+	// FilterMode when it is set.
+	FilterFunc FilterFunc
+
+	// Whether the text typed by the user is also matched against the description of each option
+	ShouldFilterDescription bool
+
+	// Whether the text typed by the user is also matched against the ID of each option
+	ShouldFilterID bool
+
+	// The minimum number of options that must be selected before the user can submit. Zero means there is no minimum.
+	MinSelected int
+
+	// The maximum number of options that can be selected. Zero means there is no maximum.
+	MaxSelected int
+
+	// Called when a key is pressed but before it is processed. Return `false` to cancel the event.
+	OnKeyFunc func(Prompt, Key) bool
+
+	// The terminal to show the prompt on. Defaults to DefaultTerminal.
+	Terminal Terminal
+
+	// The symbols and colors to draw the prompt with. Defaults to DefaultTheme.
+	Theme *Theme
+
+	list     optionList
+	selected []bool
+
+	// A message explaining why the last key press couldn't be applied
+	validationMessage string
+}
+
+// Show displays the prompt to the user and blocks the current Go routine until the user submits
+func (m *MultiSelect) Show() error {
+	return m.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (m *MultiSelect) ShowContext(ctx context.Context) error {
+	err := m.show(ctx, m.Terminal, m.Theme)
+	if err != nil {
+		return err
+	}
+	defer m.restoreAfterPanic()
+
+	answer, hasAnswer, err := m.lookupAnswer(m.ID, m.AnswerProvider)
+	if err != nil || hasAnswer {
+		if err == nil {
+			err = m.setResponse(answer)
+		}
+
+		return m.finishWithResponse(err, m.Question, m.render, m.responseNames)
+	}
+
+	if m.isLineMode() {
+		return m.showLines(ctx)
+	}
+
+	if len(m.selected) != len(m.Options) {
+		m.selected = make([]bool, len(m.Options))
+	}
+
+	m.list.numLinesShown = m.NumLinesShown
+	m.list.matcher = newOptionMatcher(m.FilterMode, m.FilterFunc, m.ShouldFilterDescription, m.ShouldFilterID)
+	m.list.setOptions(m.Options)
+	m.list.fitWidth(m.output.outputWidth, m.theme, uniseg.StringWidth(checkboxUnchecked))
+
+	m.resizeFunc = m.resize
+	m.isInputEmptyFunc = func() bool { return m.list.filter == "" }
+	m.output.hideCursor()
+	m.render(false)
+
+	for m.State() == Showing {
+		nextKey, err := m.nextKey(ctx)
+		if err != nil {
+			m.output.showCursor()
+			return m.abort(ctx, err)
+		}
+
+		m.handleInput(nextKey)
+	}
+
+	return m.closeErr
+}
+
+// resize wraps the options to the new width of the terminal and redraws the prompt.
+func (m *MultiSelect) resize() {
+	m.list.fitWidth(m.output.outputWidth, m.theme, uniseg.StringWidth(checkboxUnchecked))
+	m.render(false)
+}
+
+func (m *MultiSelect) handleInput(input Key) {
+	if m.OnKeyFunc != nil && !m.OnKeyFunc(m, input) {
+		return
+	}
+
+	m.validationMessage = ""
+
+	if input == ControlUp {
+		m.list.up()
+	} else if input == ControlDown {
+		m.list.down()
+	} else if input == ControlPageUp {
+		m.list.pageUp()
+	} else if input == ControlPageDown {
+		m.list.pageDown()
+	} else if input == ControlSpace {
+		if len(m.list.matches) != 0 {
+			m.toggle(m.list.curOptionIndex())
+		}
+	} else if input == ControlCtrlA {
+		m.selectAll()
+	} else if input == ControlCtrlN {
+		m.selectNone()
+	} else if input == ControlEnter {
+		if m.MinSelected > 0 && m.numSelected() < m.MinSelected {
+			m.validationMessage = fmt.Sprintf("select at least %d %s", m.MinSelected, pluralizeOption(m.MinSelected))
+		} else {
+			m.output.showCursor()
+			m.render(true)
+			m.finish()
+			return
+		}
+	} else if input.IsText() {
+		m.list.addToFilter(input.Rune())
+	} else if input == ControlBackspace {
+		m.list.removeFromFilter()
+	}
+
+	if m.State() != Waiting {
+		m.render(false)
+	}
+}
+
+func (m *MultiSelect) toggle(optionIndex int) {
+	if !m.selected[optionIndex] && m.MaxSelected > 0 && m.numSelected() >= m.MaxSelected {
+		m.validationMessage = fmt.Sprintf("select at most %d %s", m.MaxSelected, pluralizeOption(m.MaxSelected))
+		return
+	}
+
+	m.selected[optionIndex] = !m.selected[optionIndex]
+}
+
+// selectAll selects every option that matches the filter, stopping once MaxSelected is reached.
+func (m *MultiSelect) selectAll() {
+	for _, i := range m.list.matchedOptionIndexes() {
+		if m.selected[i] {
+			continue
+		}
+
+		if m.MaxSelected > 0 && m.numSelected() >= m.MaxSelected {
+			m.validationMessage = fmt.Sprintf("select at most %d %s", m.MaxSelected, pluralizeOption(m.MaxSelected))
+			return
+		}
+
+		m.selected[i] = true
+	}
+}
+
+// selectNone deselects every option that matches the filter.
+func (m *MultiSelect) selectNone() {
+	for _, i := range m.list.matchedOptionIndexes() {
+		m.selected[i] = false
+	}
+}
+
+func (m *MultiSelect) numSelected() int {
+	num := 0
+	for _, isSelected := range m.selected {
+		if isSelected {
+			num++
+		}
+	}
+
+	return num
+}
+
+func (m *MultiSelect) NumLinesToShow() int {
+	return m.list.numLinesToShow()
+}
+
+func (m *MultiSelect) render(isFinished bool) {
+	m.output.clear()
+
+	m.writeQuestion(m.Question)
+	m.output.write(": ")
+	if isFinished {
+		m.writeAnswer(m.responseNames())
+		return
+	} else {
+		m.writeHint("(Space to toggle) (Type to filter)")
+	}
+	m.output.nextLine()
+
+	m.list.setHeight(m.output.outputHeight - m.output.cursorRow)
+	m.list.render(m.output, m.theme, m.checkbox)
+
+	if m.validationMessage != "" {
+		m.output.nextLine()
+		m.writeError(m.validationMessage)
+	}
+
+	m.output.flush()
+}
+
+// showLines reads a comma separated list of the IDs or names of the selected options without the interactive prompt.
+func (m *MultiSelect) showLines(ctx context.Context) error {
+	line, err := m.readLine(ctx, m.Question)
+	if err == nil {
+		err = m.setResponse(line)
+	}
+
+	return m.finishWithResponse(err, m.Question, m.render, m.responseNames)
+}
+
+// setResponse selects the options given by a comma separated list of IDs or names.
+func (m *MultiSelect) setResponse(response string) error {
+	selected := make([]bool, len(m.Options))
+	for _, item := range strings.Split(response, ",") {
+		item = strings.TrimSpace(item)
+		if item == "" {
+			continue
+		}
+
+		optionIndex := findOption(m.Options, item)
+		if optionIndex == -1 {
+			return &ValidationError{Message: fmt.Sprintf("%q doesn't match any option", item)}
+		}
+
+		selected[optionIndex] = true
+	}
+
+	m.selected = selected
+
+	if m.MinSelected > 0 && m.numSelected() < m.MinSelected {
+		return &ValidationError{Message: fmt.Sprintf("select at least %d %s", m.MinSelected, pluralizeOption(m.MinSelected))}
+	}
+
+	if m.MaxSelected > 0 && m.numSelected() > m.MaxSelected {
+		return &ValidationError{Message: fmt.Sprintf("select at most %d %s", m.MaxSelected, pluralizeOption(m.MaxSelected))}
+	}
+
+	return nil
+}
+
+// responseNames returns the names of the selected options separated by commas.
+func (m *MultiSelect) responseNames() string {
+	names := make([]string, 0, m.numSelected())
+	for _, option := range m.Response() {
+		names = append(names, option.Name)
+	}
+
+	return strings.Join(names, ", ")
+}
+
+const (
+	checkboxChecked   = "[x] "
+	checkboxUnchecked = "[ ] "
+)
+
+func (m *MultiSelect) checkbox(optionIndex int) string {
+	if m.selected[optionIndex] {
+		return checkboxChecked
+	}
+
+	return checkboxUnchecked
+}
+
+// Response returns the selected options in the order that they appear in Options.
+func (m *MultiSelect) Response() []SelectionOption {
+	var response []SelectionOption
+	for i, isSelected := range m.selected {
+		if isSelected {
+			response = append(response, m.Options[i])
+		}
+	}
+
+	return response
+}
+
+func pluralizeOption(num int) string {
+	if num == 1 {
+		return "option"
+	}
+
+	return "options"
+}
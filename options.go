@@ -0,0 +1,508 @@
+package prompt
+
+import (
+	"fmt"
+	"github.com/rivo/uniseg"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+const defaultNumLinesShown = 7
+
+const moreChoicesHint = "(Move up and down to reveal more choices)"
+
+type SelectionOption struct {
+	ID          string
+	Name        string
+	Description string
+}
+
+type line struct {
+	text    string
+	isFirst bool
+
+	// The indexes of the runes in the text that matched the filter
+	highlighted []int
+
+	// The style of each rune in the text given by the markup of the description. Nil when there isn't any markup.
+	styles []textStyle
+}
+
+// optionMatch is an option that matches the filter.
+type optionMatch struct {
+	FilterMatch
+	optionIndex int
+}
+
+// optionList is the scrollable and filterable list of options that is shared by Select and MultiSelect. The options
+// that match the filter are kept up to date as the filter changes and only the options in the visible window are
+// wrapped into lines, so the cost of a key press doesn't grow with the number of options.
+type optionList struct {
+	options       []SelectionOption
+	numLinesShown int
+	matcher       optionMatcher
+
+	// The index of the match under the cursor
+	cursor int
+	filter string
+
+	width       int
+	longestName int
+
+	// The number of rows that the list can take up, including the hint. Zero means there is no limit.
+	height int
+
+	// The number of lines that the options take up, counted up to one more than the number of lines shown
+	numOptionLines int
+
+	// The options that match the filter, in the order that they are listed
+	matches []optionMatch
+
+	// The matches for each shorter filter, so that removing from the filter doesn't have to match the options again
+	previousMatches []previousMatches
+}
+
+type previousMatches struct {
+	matches []optionMatch
+	cursor  int
+}
+
+// setOptions replaces the options and matches them against the filter.
+func (l *optionList) setOptions(options []SelectionOption) {
+	l.options = options
+	l.longestName = 0
+	for _, option := range l.options {
+		l.longestName = max(uniseg.StringWidth(option.Name), l.longestName)
+	}
+
+	l.previousMatches = nil
+	l.matches = l.matchOptions(nil)
+	l.cursor = min(l.cursor, max(0, len(l.matches)-1))
+}
+
+// setWidth changes the width that the lines of the options have to fit within.
+func (l *optionList) setWidth(width int) {
+	l.width = width
+
+	limit := l.numLinesShownSetting() + 1
+	l.numOptionLines = 0
+	for _, option := range l.options {
+		if l.numOptionLines >= limit {
+			break
+		}
+
+		l.numOptionLines += len(wrapString(option.Description, l.descriptionWidth()))
+	}
+}
+
+// fitWidth sets the width of the options to what's left of the output width after the cursor prefix of the theme and
+// the marker written in front of each option.
+func (l *optionList) fitWidth(outputWidth int, theme *Theme, markerWidth int) {
+	l.setWidth(outputWidth - uniseg.StringWidth(theme.CursorPrefix) - markerWidth)
+}
+
+// up moves the cursor to the previous option that matches the filter.
+func (l *optionList) up() {
+	if len(l.matches) <= 1 {
+		return
+	}
+
+	l.cursor--
+	if l.cursor < 0 {
+		l.cursor = len(l.matches) - 1
+	}
+}
+
+// down moves the cursor to the next option that matches the filter.
+func (l *optionList) down() {
+	if len(l.matches) <= 1 {
+		return
+	}
+
+	l.cursor++
+	if l.cursor == len(l.matches) {
+		l.cursor = 0
+	}
+}
+
+// pageUp moves the cursor back by as many options as fit in the visible window, stopping at the first option.
+func (l *optionList) pageUp() {
+	l.cursor = max(0, l.cursor-l.numOptionsThatFit(l.cursor-1, -1))
+}
+
+// pageDown moves the cursor to the first option after the ones in the visible window, stopping at the last option.
+func (l *optionList) pageDown() {
+	l.cursor = max(0, min(len(l.matches)-1, l.cursor+l.numOptionsThatFit(l.cursor, 1)))
+}
+
+// numOptionsThatFit counts the matches, starting from the given one and moving in the given direction, whose lines all
+// fit in the visible window. It's at least one so that paging always moves.
+func (l *optionList) numOptionsThatFit(matchIndex int, direction int) int {
+	numOptions := 0
+	numLines := 0
+	for ; matchIndex >= 0 && matchIndex < len(l.matches); matchIndex += direction {
+		numLines += len(l.layoutOption(matchIndex))
+		if numLines > l.numLinesToShow() {
+			break
+		}
+
+		numOptions++
+	}
+
+	return max(1, numOptions)
+}
+
+// addToFilter appends to the filter. When the matcher is narrowing, only the options that matched before are checked.
+func (l *optionList) addToFilter(r rune) {
+	optionIndex := l.curOptionIndex()
+
+	l.previousMatches = append(l.previousMatches, previousMatches{matches: l.matches, cursor: l.cursor})
+	l.filter += string(r)
+	if l.matcher.isNarrowing {
+		l.matches = l.matchOptions(l.matches)
+	} else {
+		l.matches = l.matchOptions(nil)
+	}
+
+	l.moveAfterFilter(optionIndex)
+}
+
+// removeFromFilter removes the last character of the filter.
+func (l *optionList) removeFromFilter() {
+	if l.filter == "" {
+		return
+	}
+
+	optionIndex := l.curOptionIndex()
+
+	_, size := utf8.DecodeLastRuneInString(l.filter)
+	l.filter = l.filter[:len(l.filter)-size]
+	if len(l.previousMatches) > 0 {
+		previous := l.previousMatches[len(l.previousMatches)-1]
+		l.previousMatches = l.previousMatches[:len(l.previousMatches)-1]
+		l.matches = previous.matches
+
+		// Go back to where the cursor was if nothing matched the longer filter.
+		if optionIndex == -1 {
+			l.cursor = previous.cursor
+			return
+		}
+	} else {
+		l.matches = l.matchOptions(nil)
+	}
+
+	l.moveAfterFilter(optionIndex)
+}
+
+// moveAfterFilter moves the cursor after the filter changes. The cursor stays on the same option if it still matches,
+// unless the options are ranked, in which case it moves to the best match.
+func (l *optionList) moveAfterFilter(optionIndex int) {
+	l.cursor = 0
+	if !l.isRanked() {
+		l.moveToOption(optionIndex)
+	}
+}
+
+// moveToOption moves the cursor to the option if it matches the filter.
+func (l *optionList) moveToOption(optionIndex int) {
+	if !l.isRanked() {
+		// The matches are in the same order as the options.
+		i := sort.Search(len(l.matches), func(i int) bool {
+			return l.matches[i].optionIndex >= optionIndex
+		})
+		if i < len(l.matches) && l.matches[i].optionIndex == optionIndex {
+			l.cursor = i
+		}
+
+		return
+	}
+
+	for i, match := range l.matches {
+		if match.optionIndex == optionIndex {
+			l.cursor = i
+			return
+		}
+	}
+}
+
+// isRanked returns whether the matches are listed by score rather than in the order of the options.
+func (l *optionList) isRanked() bool {
+	return l.matcher.isRanked && l.filter != ""
+}
+
+// curOptionIndex returns the index of the option under the cursor, or -1 if no options match the filter.
+func (l *optionList) curOptionIndex() int {
+	if len(l.matches) == 0 {
+		return -1
+	}
+
+	return l.matches[l.cursor].optionIndex
+}
+
+func (l *optionList) curOption() SelectionOption {
+	optionIndex := l.curOptionIndex()
+	if optionIndex == -1 {
+		return SelectionOption{}
+	}
+
+	return l.options[optionIndex]
+}
+
+// matchedOptionIndexes returns the indexes of the options that match the filter in the order that they are listed.
+func (l *optionList) matchedOptionIndexes() []int {
+	indexes := make([]int, len(l.matches))
+	for i, match := range l.matches {
+		indexes[i] = match.optionIndex
+	}
+
+	return indexes
+}
+
+// matchOptions matches the candidates against the filter and ranks them. Nil candidates means every option.
+func (l *optionList) matchOptions(candidates []optionMatch) []optionMatch {
+	if candidates == nil {
+		candidates = make([]optionMatch, len(l.options))
+		for i := range candidates {
+			candidates[i].optionIndex = i
+		}
+	}
+
+	if l.filter == "" {
+		return candidates
+	}
+
+	match := l.matcher.match
+	if match == nil {
+		match = newOptionMatcher(FilterContains, nil, false, false).match
+	}
+
+	matches := make([]optionMatch, 0, len(candidates))
+	for _, candidate := range candidates {
+		filterMatch, ok := match(l.filter, l.options[candidate.optionIndex])
+		if ok {
+			matches = append(matches, optionMatch{FilterMatch: filterMatch, optionIndex: candidate.optionIndex})
+		}
+	}
+
+	if l.isRanked() {
+		// Ties are broken by the order of the options, since the candidates might already be ranked.
+		sort.Slice(matches, func(i, j int) bool {
+			if matches[i].Score != matches[j].Score {
+				return matches[i].Score > matches[j].Score
+			}
+
+			return matches[i].optionIndex < matches[j].optionIndex
+		})
+	}
+
+	return matches
+}
+
+func (l *optionList) numLinesShownSetting() int {
+	if l.numLinesShown <= 0 {
+		return defaultNumLinesShown
+	}
+
+	return l.numLinesShown
+}
+
+// setHeight changes the number of rows that the list can take up, so that it's never taller than the terminal.
+func (l *optionList) setHeight(height int) {
+	l.height = height
+}
+
+func (l *optionList) numLinesToShow() int {
+	numLines := min(l.numLinesShownSetting(), l.numOptionLines)
+	if l.height <= 0 {
+		return numLines
+	}
+
+	// Leave room for the hint whenever some of the lines are hidden.
+	numHintRows := len(wrapString(moreChoicesHint, l.width))
+	if numLines > l.height || numLines < l.numOptionLines && numLines+numHintRows > l.height {
+		numLines = l.height - numHintRows
+	}
+
+	return max(1, numLines)
+}
+
+// render writes the visible window of lines, starting at the option under the cursor and looping back to the first
+// option. The marker function returns the text placed between the cursor and the name of an option, continuation
+// lines are indented by the same amount.
+func (l *optionList) render(o *output, theme *Theme, marker func(optionIndex int) string) {
+	numLinesToShow := l.numLinesToShow()
+	numLinesWritten := 0
+
+	if len(l.matches) == 0 {
+		if o.isMonochrome() {
+			o.writeLn(l.filter + " (no matches)")
+		} else {
+			o.writeStyleLn(l.filter, theme.ErrorStyle)
+		}
+		numLinesWritten++
+	}
+
+	for i := 0; i < len(l.matches) && numLinesWritten < numLinesToShow; i++ {
+		matchIndex := (l.cursor + i) % len(l.matches)
+		optionIndex := l.matches[matchIndex].optionIndex
+
+		for _, line := range l.layoutOption(matchIndex) {
+			if numLinesWritten == numLinesToShow {
+				break
+			}
+
+			l.renderLine(o, theme, line, optionIndex, matchIndex == l.cursor, marker)
+			numLinesWritten++
+		}
+	}
+
+	// Keep the height of the prompt the same when there aren't enough options to fill the window.
+	for ; numLinesWritten < numLinesToShow; numLinesWritten++ {
+		o.nextLine()
+	}
+
+	if l.numOptionLines > numLinesToShow {
+		o.writeWrapped(moreChoicesHint, theme.HintStyle, 0)
+	}
+}
+
+// renderLine writes a single line, highlighting the runes that matched the filter.
+func (l *optionList) renderLine(o *output, theme *Theme, line line, optionIndex int, isCursorOption bool, marker func(optionIndex int) string) {
+	if isCursorOption && line.isFirst {
+		o.writeStyle(theme.CursorPrefix, theme.CursorStyle)
+	} else {
+		o.write(strings.Repeat(" ", uniseg.StringWidth(theme.CursorPrefix)))
+	}
+
+	if marker != nil {
+		if line.isFirst {
+			o.write(marker(optionIndex))
+		} else {
+			o.write(strings.Repeat(" ", uniseg.StringWidth(marker(optionIndex))))
+		}
+	}
+
+	runes := []rune(line.text)
+	isHighlighted := make([]bool, len(runes))
+	for _, position := range line.highlighted {
+		if position >= 0 && position < len(runes) {
+			isHighlighted[position] = true
+		}
+	}
+
+	styleAt := func(i int) textStyle {
+		if i < len(line.styles) {
+			return line.styles[i]
+		}
+
+		return textStyle{}
+	}
+
+	base := textStyle{}
+	if isCursorOption {
+		base.style = theme.AnswerStyle
+	}
+
+	// Write runs of runes that share the same style.
+	runStart := 0
+	for i := 1; i <= len(runes); i++ {
+		if i < len(runes) && isHighlighted[i] == isHighlighted[runStart] && styleAt(i) == styleAt(runStart) {
+			continue
+		}
+
+		run := string(runes[runStart:i])
+		style := base.merge(styleAt(runStart))
+		if isHighlighted[runStart] && o.isMonochrome() {
+			o.write("[" + run + "]")
+		} else if isHighlighted[runStart] {
+			o.writeText(run, style.merge(textStyle{style: theme.MatchStyle}))
+		} else {
+			o.writeText(run, style)
+		}
+
+		runStart = i
+	}
+
+	o.nextLine()
+}
+
+// layoutOption wraps a matching option into lines that fit within the width.
+func (l *optionList) layoutOption(matchIndex int) []line {
+	match := l.matches[matchIndex]
+	option := l.options[match.optionIndex]
+	numNameRunes := utf8.RuneCountInString(option.Name)
+
+	description, descriptionStyles := parseMarkupRunes(option.Description)
+	wrappedDescription := wrapRanges(description, l.descriptionWidth(), l.descriptionWidth())
+	lines := make([]line, 0, len(wrappedDescription))
+
+	for i, wrappedRange := range wrappedDescription {
+		wrapped := description[wrappedRange.start:wrappedRange.end]
+
+		// The spaces that lines are broken at are left out, so each line is found by its place in the description.
+		descriptionStart := utf8.RuneCountInString(description[:wrappedRange.start])
+
+		var currentLineText string
+		var highlighted []int
+		var descriptionColumn int
+		if i == 0 {
+			padding := strings.Repeat(" ", l.longestName-uniseg.StringWidth(option.Name))
+			currentLineText = fmt.Sprintf("%s: %s%s", option.Name, padding, wrapped)
+			descriptionColumn = numNameRunes + 2 + len(padding)
+
+			for _, position := range match.NamePositions {
+				if position < numNameRunes {
+					highlighted = append(highlighted, position)
+				}
+			}
+		} else {
+			currentLineText = fmt.Sprintf("%s  %s", strings.Repeat(" ", l.longestName), wrapped)
+			descriptionColumn = l.longestName + 2
+		}
+
+		numWrappedRunes := utf8.RuneCountInString(wrapped)
+		for _, position := range match.DescriptionPositions {
+			if position >= descriptionStart && position < descriptionStart+numWrappedRunes {
+				highlighted = append(highlighted, descriptionColumn+position-descriptionStart)
+			}
+		}
+
+		var styles []textStyle
+		if descriptionStyles != nil {
+			styles = make([]textStyle, descriptionColumn, descriptionColumn+numWrappedRunes)
+			styles = append(styles, descriptionStyles[descriptionStart:descriptionStart+numWrappedRunes]...)
+		}
+
+		lines = append(lines, line{
+			text:        currentLineText,
+			isFirst:     i == 0,
+			highlighted: highlighted,
+			styles:      styles,
+		})
+	}
+
+	return lines
+}
+
+func (l *optionList) descriptionWidth() int {
+	return l.width - l.longestName - 2
+}
+
+// findOption returns the index of the option whose ID matches the response, falling back to an option whose name
+// matches ignoring case. It returns -1 when nothing matches.
+func findOption(options []SelectionOption, response string) int {
+	for i, option := range options {
+		if option.ID == response {
+			return i
+		}
+	}
+
+	for i, option := range options {
+		if strings.EqualFold(option.Name, response) {
+			return i
+		}
+	}
+
+	return -1
+}
@@ -0,0 +1,149 @@
+package prompt_test
+
+import (
+	"github.com/JosephNaberhaus/prompt"
+	"github.com/JosephNaberhaus/prompt/prompttest"
+	"testing"
+)
+
+func TestSelectAlignsNamesByDisplayWidth(t *testing.T) {
+	terminal := prompttest.NewTerminal(34, 12, prompt.Noop, prompt.ControlEnter)
+
+	var shown snapshot
+	p := prompt.Select{
+		Question: "Where to?",
+		Options: []prompt.SelectionOption{
+			{Name: "東京", Description: "The capital of Japan"},
+			// A woman and a laptop joined by a zero width joiner
+			{Name: "👩‍💻", Description: "Anywhere with good wifi"},
+			{Name: "Gene\u0300ve", Description: "A city on a lake in Switzerland"},
+			{Name: "Oslo", Description: "Fjords"},
+		},
+		Terminal:  terminal,
+		OnKeyFunc: snapshotOnNoop(terminal, &shown),
+	}
+
+	err := p.Show()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	expected := "" +
+		"? Where to?: (Use arrow keys)\n" +
+		"  (Type to filter)\n" +
+		"> 東京:   The capital of Japan\n" +
+		"  👩‍💻:     Anywhere with good wifi\n" +
+		"  Gene\u0300ve: A city on a lake in\n" +
+		"          Switzerland\n" +
+		"  Oslo:   Fjords"
+	assertShown(t, shown, expected)
+}
+
+func TestOptionsFitAfterCursorPrefix(t *testing.T) {
+	theme := prompt.ThemeClassic
+	theme.CursorPrefix = "---> "
+
+	options := []prompt.SelectionOption{
+		{Name: "api", Description: "Handles the requests"},
+		{Name: "worker", Description: "Handles events"},
+	}
+
+	t.Run("select", func(t *testing.T) {
+		terminal := prompttest.NewTerminal(30, 12, prompt.Noop, prompt.ControlEnter)
+
+		var shown snapshot
+		p := prompt.Select{
+			Question:  "Which service?",
+			Options:   options,
+			Theme:     &theme,
+			Terminal:  terminal,
+			OnKeyFunc: snapshotOnNoop(terminal, &shown),
+		}
+
+		err := p.Show()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "" +
+			"? Which service?: (Use arrow\n" +
+			"  keys) (Type to filter)\n" +
+			"---> api:    Handles the\n" +
+			"             requests\n" +
+			"     worker: Handles events"
+		assertShown(t, shown, expected)
+	})
+
+	t.Run("multiselect", func(t *testing.T) {
+		terminal := prompttest.NewTerminal(30, 12, prompt.Noop, prompt.ControlEnter)
+
+		var shown snapshot
+		p := prompt.MultiSelect{
+			Question:  "Which services?",
+			Options:   options,
+			Theme:     &theme,
+			Terminal:  terminal,
+			OnKeyFunc: snapshotOnNoop(terminal, &shown),
+		}
+
+		err := p.Show()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "" +
+			"? Which services?: (Space to\n" +
+			"  toggle) (Type to filter)\n" +
+			"---> [ ] api:    Handles the\n" +
+			"                 requests\n" +
+			"     [ ] worker: Handles\n" +
+			"                 events"
+		assertShown(t, shown, expected)
+	})
+}
+
+func TestFinishedAnswerWrapsInLineWithQuestion(t *testing.T) {
+	options := []prompt.SelectionOption{
+		{Name: "api", Description: "runs with [yellow]--replicas=3[/] behind the gateway"},
+		{Name: "background-worker"},
+	}
+
+	t.Run("select", func(t *testing.T) {
+		terminal := prompttest.NewTerminal(30, 12, prompt.ControlEnter)
+		p := prompt.Select{
+			Question: "Deploy",
+			Options:  options,
+			Terminal: terminal,
+		}
+
+		err := p.Show()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "" +
+			"? Deploy: api: runs with --\n" +
+			"  replicas=3 behind the\n" +
+			"  gateway"
+		prompttest.AssertScreen(t, terminal.Screen, expected)
+	})
+
+	t.Run("multiselect", func(t *testing.T) {
+		terminal := prompttest.NewTerminal(30, 12, prompt.ControlCtrlA, prompt.ControlEnter)
+		p := prompt.MultiSelect{
+			Question: "Deploy",
+			Options:  options,
+			Terminal: terminal,
+		}
+
+		err := p.Show()
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		expected := "" +
+			"? Deploy: api, background-\n" +
+			"  worker"
+		prompttest.AssertScreen(t, terminal.Screen, expected)
+	})
+}
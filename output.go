@@ -1,63 +1,136 @@
 package prompt
 
 import (
-	"fmt"
+	"bytes"
+	editor "github.com/JosephNaberhaus/texteditor"
 	"github.com/rivo/uniseg"
 	escapes "github.com/snugfox/ansi-escapes"
-	"os"
+	"io"
 	"strings"
-	"unicode"
 )
 
+// Escape sequences that ask the terminal to show everything between them at once, rather than drawing it as it arrives.
+// Terminals that don't support synchronized updates ignore them.
+const (
+	synchronizedUpdateBegin = "\033[?2026h"
+	synchronizedUpdateEnd   = "\033[?2026l"
+)
+
+// output draws prompts as frames. A prompt clears the frame and writes all of it each time that it renders, and flushing
+// only sends the lines that changed since the last flush to the terminal.
 type output struct {
-	outputWidth             int
-	cursorColumn, cursorRow int
+	out          io.Writer
+	colorProfile ColorProfile
+
+	outputWidth, outputHeight int
+	cursorColumn, cursorRow   int
+
+	// The frame being written and the frame that was last flushed to the terminal
+	rows        []outputRow
+	flushedRows []outputRow
+
+	// The rows of the frame that can be scrolled out of view when it is taller than the terminal, and how many of them
+	// were scrolled off the top of the last frame. A start of -1 lets the whole frame scroll.
+	scrollStart, scrollEnd int
+	scrollOffset           int
+
+	// Where the terminal's cursor is, relative to the first row of the output
+	terminalRow, terminalColumn int
+
+	// The furthest row that the output has reached on the terminal. The cursor can't be moved past the bottom of the
+	// terminal, so rows after it are started with newlines.
+	lastTerminalRow int
+
+	// Escape sequences that are sent before the changes to the frame on the next flush
+	buffer strings.Builder
+
+	// Whether the prompt has hidden the cursor
+	isCursorHidden bool
+}
+
+type outputRow struct {
+	// The text of the row along with the escape sequences that style it
+	content []byte
 
-	numExtraLinesInBuffer int
-	numExtraLinesWritten  int
-	buffer                strings.Builder
+	// The number of columns that have been written to
+	width int
+
+	// Whether the terminal wrapped onto this row from the previous one, rather than it being started by a newline
+	isWrapped bool
+}
+
+func (r outputRow) equal(other outputRow) bool {
+	return r.width == other.width && r.isWrapped == other.isWrapped && bytes.Equal(r.content, other.content)
 }
 
-func newOutput() (*output, error) {
-	dimensions, err := escapes.GetConsoleSize(os.Stdout.Fd())
+func newOutput(terminal Terminal) (*output, error) {
+	width, height, err := terminal.Size()
 	if err != nil {
-		return nil, fmt.Errorf("couldn't get console size: %v", err)
+		return nil, err
 	}
 
-	return &output{outputWidth: dimensions.Cols}, nil
+	return &output{
+		out:          terminal,
+		colorProfile: DefaultColorProfile,
+		outputWidth:  width,
+		outputHeight: height,
+		scrollStart:  -1,
+	}, nil
 }
 
 func (o *output) write(content string) {
 	gc := uniseg.NewGraphemes(content)
 	for gc.Next() {
 		current := gc.Str()
-		isNonAscii := len(current) != 1 && current[0] > unicode.MaxASCII
-
-		if isNonAscii {
-			o.buffer.WriteString(escapes.CursorSavePosition)
-		}
-
 		if current == "\n" {
 			o.nextLine()
 			continue
 		}
 
-		o.buffer.WriteString(current)
-
-		if isNonAscii {
-			o.buffer.WriteString(escapes.CursorRestorePosition)
-			o.buffer.WriteString(escapes.CursorForward)
+		// Wide characters, such as CJK and most emoji, take up two columns. The terminal moves a character that doesn't
+		// fit in the rest of the row onto the next one.
+		width := gc.Width()
+		if o.cursorColumn+width > o.outputWidth && o.cursorColumn > 0 {
+			o.wrapCursor()
 		}
 
-		o.cursorColumn++
-		o.wrapCursor()
+		row := o.row(o.cursorRow)
+		row.content = append(row.content, current...)
+		o.cursorColumn += width
+		row.width = max(row.width, o.cursorColumn)
+	}
+}
+
+// writeEscape writes an escape sequence, such as one that changes the style, at the cursor.
+func (o *output) writeEscape(sequence string) {
+	row := o.row(o.cursorRow)
+	row.content = append(row.content, sequence...)
+}
+
+// row returns a row of the frame, adding it if it hasn't been written to yet.
+func (o *output) row(index int) *outputRow {
+	for len(o.rows) <= index {
+		o.rows = append(o.rows, outputRow{})
 	}
+
+	return &o.rows[index]
 }
 
-func (o *output) writeColor(content string, c color) {
-	o.buffer.WriteString(c.toTextEscapes())
+func (o *output) writeStyle(content string, style Style) {
+	styleEscapes := style.escapes(o.colorProfile)
+	if styleEscapes == "" {
+		o.write(content)
+		return
+	}
+
+	o.writeEscape(styleEscapes)
 	o.write(content)
-	o.buffer.WriteString(colorWhite.toTextEscapes())
+	o.writeEscape(escapes.ColorReset)
+}
+
+// isMonochrome returns whether styles are drawn without color, in which case text markers have to be used instead.
+func (o *output) isMonochrome() bool {
+	return o.colorProfile == ColorProfileMonochrome
 }
 
 func (o *output) writeLn(content string) {
@@ -65,91 +138,482 @@ func (o *output) writeLn(content string) {
 	o.nextLine()
 }
 
-func (o *output) writeColorLn(content string, color color) {
-	o.writeColor(content, color)
+func (o *output) writeStyleLn(content string, style Style) {
+	o.writeStyle(content, style)
 	o.nextLine()
 }
 
+// setCursor sets where the cursor is left once the frame has been drawn.
 func (o *output) setCursor(row, col int) {
-	if row > o.numExtraLinesWritten {
-		o.nextLine()
+	o.row(row)
+	o.cursorRow, o.cursorColumn = row, col
+}
+
+func (o *output) nextLine() {
+	o.cursorRow++
+	o.cursorColumn = 0
+	o.row(o.cursorRow)
+}
+
+// wrapCursor moves the cursor to the start of the next row, as the terminal does when text runs past the end of a row.
+func (o *output) wrapCursor() {
+	o.cursorRow++
+	o.cursorColumn = 0
+	o.row(o.cursorRow).isWrapped = true
+}
+
+func (o *output) hideCursor() {
+	o.isCursorHidden = true
+	o.buffer.WriteString(escapes.CursorHide)
+}
+
+func (o *output) showCursor() {
+	o.isCursorHidden = false
+	o.buffer.WriteString(escapes.CursorShow)
+}
+
+// suspend erases the frame and shows the cursor so that the terminal can be used by something else.
+func (o *output) suspend() {
+	o.clear()
+	if o.isCursorHidden {
+		o.buffer.WriteString(escapes.CursorShow)
 	}
+	o.flush()
+}
+
+// resume starts drawing again at the new size from wherever the cursor was left, hiding the cursor again if it was
+// hidden before suspend.
+func (o *output) resume(width, height int) {
+	o.startOver(width, height)
+	if o.isCursorHidden {
+		o.buffer.WriteString(escapes.CursorHide)
+	}
+}
+
+// clear starts a new frame.
+func (o *output) clear() {
+	o.rows = nil
+	o.cursorRow, o.cursorColumn = 0, 0
+	o.scrollStart, o.scrollEnd = -1, 0
+}
+
+// startScrolling marks the rows written from the cursor's row onwards as the part of the frame that scrolls when it is
+// taller than the terminal. The rows before them, such as the question, stay in view.
+func (o *output) startScrolling() {
+	o.scrollStart = o.cursorRow
+	o.scrollEnd = len(o.rows)
+}
 
-	o.moveCursor(row-o.cursorRow, col-o.cursorColumn)
+// stopScrolling ends the part of the frame that scrolls after the cursor's row.
+func (o *output) stopScrolling() {
+	o.scrollEnd = o.cursorRow + 1
 }
 
-func (o *output) moveCursor(numRows, numCols int) {
-	if numRows == 0 && numCols == 0 {
+// fitToHeight removes rows from the frame until it fits on the terminal, since rows that scroll off the top of the
+// terminal can't be reached again. The rows are removed from the part of the frame that scrolls, keeping the cursor in
+// view and moving the rows as little as possible since the last frame.
+func (o *output) fitToHeight() {
+	numExtraRows := len(o.rows) - o.outputHeight
+	if o.outputHeight <= 0 || numExtraRows <= 0 {
+		o.scrollOffset = 0
 		return
 	}
 
-	o.cursorColumn += numCols
-	o.cursorRow += numRows
+	start, end := o.scrollStart, min(o.scrollEnd, len(o.rows))
+	if start < 0 || end-start <= numExtraRows {
+		start, end = 0, len(o.rows)
+	}
+
+	numVisible := end - start - numExtraRows
+	offset := min(o.scrollOffset, numExtraRows)
+	if o.cursorRow >= start && o.cursorRow < end {
+		if o.cursorRow < start+offset {
+			offset = o.cursorRow - start
+		} else if o.cursorRow >= start+offset+numVisible {
+			offset = o.cursorRow - start - numVisible + 1
+		}
+	}
+	o.scrollOffset = offset
+
+	rows := make([]outputRow, 0, o.outputHeight)
+	rows = append(rows, o.rows[:start]...)
+	rows = append(rows, o.rows[start+offset:start+offset+numVisible]...)
+	rows = append(rows, o.rows[end:]...)
+
+	// Rows that were wrapped onto from a row that was removed now start a line of their own.
+	rows[start].isWrapped = false
+	if end < len(o.rows) {
+		rows[start+numVisible].isWrapped = false
+	}
+
+	if o.cursorRow >= end {
+		o.cursorRow -= numExtraRows
+	} else if o.cursorRow >= start {
+		o.cursorRow -= offset
+	}
 
-	o.buffer.WriteString(escapes.CursorMove(numCols, numRows))
+	o.rows = rows
 }
 
-func (o *output) nextLine() {
-	if o.cursorColumn == o.outputWidth {
-		return
+// resize erases everything that has been output and starts again at the new size. The terminal reflows the rows that
+// it wrapped when its width changes, so the distance to the start of the output is worked out at the new width.
+func (o *output) resize(width, height int) {
+	isWrappedRow := func(index int) bool {
+		return index < len(o.flushedRows) && o.flushedRows[index].isWrapped
+	}
+
+	rowsAboveCursor := 0
+	lineWidth := 0
+	for row := 0; row <= o.terminalRow; row++ {
+		if row > 0 && !isWrappedRow(row) {
+			// A previous line ended, so count how many rows it takes up now.
+			rowsAboveCursor += max(1, (lineWidth+width-1)/width)
+			lineWidth = 0
+		}
+
+		if row == o.terminalRow {
+			lineWidth += o.terminalColumn
+		} else if isWrappedRow(row + 1) {
+			lineWidth += o.outputWidth
+		} else if row < len(o.flushedRows) {
+			lineWidth += o.flushedRows[row].width
+		}
+	}
+
+	// The cursor sits on the last column, rather than the next row, when it reaches the end of a row.
+	if lineWidth > 0 && lineWidth%width == 0 {
+		lineWidth--
+	}
+	rowsAboveCursor += lineWidth / width
+
+	o.buffer.WriteString(escapes.CursorLeft)
+	o.buffer.WriteString(escapes.CursorMove(0, -rowsAboveCursor))
+	o.buffer.WriteString(escapes.EraseDown)
+
+	o.startOver(width, height)
+}
+
+// startOver forgets what has been drawn and changes the size, so that the next frame is drawn in full from the
+// terminal's cursor.
+func (o *output) startOver(width, height int) {
+	o.outputWidth, o.outputHeight = width, height
+	o.clear()
+	o.flushedRows = nil
+	o.terminalRow, o.terminalColumn = 0, 0
+	o.lastTerminalRow = 0
+}
+
+// flush draws the changes to the frame on the terminal.
+func (o *output) flush() {
+	o.fitToHeight()
+	o.drawChanges()
+	o.writeBuffer()
+}
+
+// drawChanges adds what is needed to change the terminal from the last frame that was flushed to the current one to
+// the buffer. Only the lines that changed are drawn again, and the update is synchronized so that it appears at once.
+func (o *output) drawChanges() {
+	changes := strings.Builder{}
+
+	for start := 0; start < len(o.rows); {
+		// A line is drawn along with the rows that it wraps onto so that the terminal wraps it in the same way.
+		end := start + 1
+		for end < len(o.rows) && o.rows[end].isWrapped {
+			end++
+		}
+
+		if !o.isLineFlushed(start, end) {
+			o.drawLine(&changes, start, end)
+		}
+
+		start = end
+	}
+
+	if len(o.flushedRows) > len(o.rows) {
+		o.moveTerminalCursor(&changes, len(o.rows), 0)
+		changes.WriteString(escapes.EraseDown)
 	}
 
-	if o.cursorRow+1 < o.numExtraLinesWritten {
-		o.setCursor(o.cursorRow+1, 0)
+	o.moveTerminalCursor(&changes, o.cursorRow, o.cursorColumn)
+
+	// The rows are kept as they are, and appending to them leaves the flushed rows unchanged.
+	o.flushedRows = o.rows
+	o.rows = append([]outputRow(nil), o.rows...)
+
+	if changes.Len() == 0 && o.buffer.Len() == 0 {
 		return
 	}
 
-	o.buffer.WriteString("\n ")
-	o.cursorRow++
-	o.cursorColumn = 1
+	pending := o.buffer.String()
+	o.buffer.Reset()
+
+	o.buffer.WriteString(synchronizedUpdateBegin)
+	o.buffer.WriteString(pending)
+	o.buffer.WriteString(changes.String())
+	o.buffer.WriteString(synchronizedUpdateEnd)
+}
+
+// isLineFlushed returns whether the line made up of the rows from start to end is already on the terminal.
+func (o *output) isLineFlushed(start, end int) bool {
+	if end > len(o.flushedRows) || end < len(o.flushedRows) && o.flushedRows[end].isWrapped {
+		return false
+	}
 
-	o.moveCursor(0, -1)
+	for row := start; row < end; row++ {
+		if !o.rows[row].equal(o.flushedRows[row]) {
+			return false
+		}
+	}
 
-	o.numExtraLinesWritten = max(o.cursorRow, o.numExtraLinesWritten)
-	o.numExtraLinesInBuffer = max(o.cursorRow, o.numExtraLinesInBuffer)
+	return true
 }
 
-func (o *output) wrapCursor() {
-	deltaRow := (o.cursorColumn - 1) / o.outputWidth
-	o.cursorRow += deltaRow
-	o.cursorColumn -= deltaRow * o.outputWidth
+// drawLine draws the line made up of the rows from start to end over whatever the terminal shows there. Drawing starts
+// from the first part of the line that changed.
+func (o *output) drawLine(changes *strings.Builder, start, end int) {
+	// The terminal keeps wrapping onto the rows after the ones that are skipped as long as they were wrapped before.
+	first := start
+	for first+1 < end && first+1 < len(o.flushedRows) && o.flushedRows[first+1].isWrapped && o.rows[first].equal(o.flushedRows[first]) {
+		first++
+	}
+
+	position := rowPosition{}
+	if first < len(o.flushedRows) && o.rows[first].isWrapped == o.flushedRows[first].isWrapped {
+		position = unchangedPosition(o.rows[first].content, o.flushedRows[first].content, o.outputWidth)
+	}
+
+	o.moveTerminalCursor(changes, first, position.column)
+	changes.WriteString(position.link)
+	changes.WriteString(position.style)
+
+	for row := first; row < end; row++ {
+		if row == first {
+			changes.Write(o.rows[row].content[position.offset:])
+		} else {
+			changes.Write(o.rows[row].content)
+		}
+
+		// Erase what was left of the row from before. A full row can't be erased past its text without also erasing the
+		// last column, but there is nothing left of it anyway.
+		if o.rows[row].width < o.outputWidth {
+			changes.WriteString(escapes.EraseRight)
+		}
+	}
 
-	o.numExtraLinesWritten = max(o.cursorRow, o.numExtraLinesWritten)
-	o.numExtraLinesInBuffer = max(o.cursorRow, o.numExtraLinesInBuffer)
+	o.terminalRow, o.terminalColumn = end-1, o.rows[end-1].width
+	o.lastTerminalRow = max(o.lastTerminalRow, o.terminalRow)
 }
 
-func (o *output) hideCursor() {
-	o.buffer.WriteString(escapes.CursorHide)
+// rowPosition is a place in the content of a row that drawing can start from.
+type rowPosition struct {
+	offset, column int
+
+	// The escape sequences that are in effect there
+	style, link string
 }
 
-func (o *output) showCursor() {
-	o.buffer.WriteString(escapes.CursorShow)
+// unchangedPosition returns the furthest place that the content of a row can be drawn from after the flushed content of
+// the row was drawn. Everything before it is the same in both. It is never past the last column, since the terminal only
+// wraps onto the next row when the last column is written to.
+func unchangedPosition(content, flushed []byte, width int) rowPosition {
+	prefixLength := 0
+	for prefixLength < len(content) && prefixLength < len(flushed) && content[prefixLength] == flushed[prefixLength] {
+		prefixLength++
+	}
+
+	// The bytes before the place are the same, but a grapheme cluster that ends there in one could continue in the
+	// other, so the place has to be the start of a cluster in both.
+	positions, flushedPositions := rowPositions(content, prefixLength), rowPositions(flushed, prefixLength)
+	i, j := len(positions)-1, len(flushedPositions)-1
+	for i > 0 {
+		switch {
+		case positions[i].offset > flushedPositions[j].offset:
+			i--
+		case positions[i].offset < flushedPositions[j].offset:
+			j--
+		case positions[i].column != flushedPositions[j].column || positions[i].column >= width:
+			i--
+			j--
+		default:
+			return positions[i]
+		}
+	}
+
+	return positions[0]
 }
 
-func (o *output) clear() {
-	for row := 0; row <= o.numExtraLinesInBuffer; row++ {
-		o.setCursor(row, 0)
-		o.buffer.WriteString(escapes.EraseLine)
+// rowPositions returns the places in the content of a row, up to the byte offset limit, where a grapheme cluster or an
+// escape sequence starts. The end of the content is included when it is within the limit.
+func rowPositions(content []byte, limit int) []rowPosition {
+	positions := []rowPosition{{}}
+	current := rowPosition{}
+
+	for current.offset < len(content) {
+		length := escapeSequenceLength(content[current.offset:])
+		if length > 0 {
+			sequence := string(content[current.offset : current.offset+length])
+			if strings.HasPrefix(sequence, "\033]8;;") {
+				current.link = sequence
+				if sequence == "\033]8;;\033\\" {
+					current.link = ""
+				}
+			} else if strings.HasSuffix(sequence, "m") {
+				current.style = sequence
+				if sequence == escapes.ColorReset {
+					current.style = ""
+				}
+			}
+		} else {
+			var width int
+			var cluster []byte
+			cluster, _, width, _ = uniseg.FirstGraphemeCluster(content[current.offset:], -1)
+			length = len(cluster)
+			current.column += width
+		}
+
+		current.offset += length
+		if current.offset > limit {
+			break
+		}
+
+		positions = append(positions, current)
 	}
 
-	o.setCursor(0, 0)
-	o.numExtraLinesInBuffer = 0
+	return positions
 }
 
-func (o *output) flush() {
-	fmt.Print(o.buffer.String())
+// escapeSequenceLength returns the length of the escape sequence at the start of the content, or 0 if there isn't one.
+// Only the sequences that are written into rows are recognized.
+func escapeSequenceLength(content []byte) int {
+	if len(content) < 2 || content[0] != '\033' {
+		return 0
+	}
+
+	switch content[1] {
+	case '[':
+		for i := 2; i < len(content); i++ {
+			if content[i] >= 0x40 && content[i] <= 0x7E {
+				return i + 1
+			}
+		}
+	case ']':
+		if end := bytes.Index(content, []byte("\033\\")); end != -1 {
+			return end + 2
+		}
+	}
+
+	return len(content)
+}
+
+// moveTerminalCursor moves the terminal's cursor to a position in the frame.
+func (o *output) moveTerminalCursor(changes *strings.Builder, row, col int) {
+	if row == o.terminalRow && col == o.terminalColumn {
+		return
+	}
+
+	changes.WriteString("\r")
+	if row > o.lastTerminalRow {
+		changes.WriteString(escapes.CursorMove(0, o.lastTerminalRow-o.terminalRow))
+		changes.WriteString(strings.Repeat("\n", row-o.lastTerminalRow))
+		o.lastTerminalRow = row
+	} else {
+		changes.WriteString(escapes.CursorMove(0, row-o.terminalRow))
+	}
+
+	changes.WriteString(escapes.CursorMove(min(col, o.outputWidth-1), 0))
+	o.terminalRow, o.terminalColumn = row, col
+}
+
+func (o *output) writeBuffer() {
+	if o.buffer.Len() == 0 {
+		return
+	}
+
+	io.WriteString(o.out, o.buffer.String())
 	o.buffer.Reset()
 }
 
+// commit draws the frame and leaves the cursor on the row after it, so that whatever is written next starts below it.
+// The whole frame is drawn even when it's taller than the terminal, since the rows above it won't be changed again.
 func (o *output) commit() {
-	o.setCursor(o.numExtraLinesInBuffer, 0)
-	o.nextLine()
-	o.flush()
+	o.drawChanges()
+
+	changes := strings.Builder{}
+	o.moveTerminalCursor(&changes, len(o.flushedRows), 0)
+	o.buffer.WriteString(changes.String())
+
+	o.writeBuffer()
+}
+
+// restore leaves the last frame that was flushed on the terminal with the cursor shown on the row after it. A frame
+// that was only partly written is thrown away.
+func (o *output) restore() {
+	o.rows = append([]outputRow(nil), o.flushedRows...)
+	o.showCursor()
+	o.commit()
 }
 
+// uncommit erases a committed frame.
 func (o *output) uncommit() {
 	o.clear()
-	o.numExtraLinesInBuffer = 0
-	o.numExtraLinesWritten = 0
 	o.flush()
 }
+
+// writeEditor writes the text of the editor in the style and returns the position that its cursor is drawn at. The
+// editor counts every grapheme cluster as one column, so the text is left for the terminal to wrap rather than using
+// the editor's lines. The editor must be as wide as the output, with its first line indented to the cursor's column.
+func (o *output) writeEditor(e *editor.TextEditor, style Style) (row, col int) {
+	cursorParagraph, cursorOffset := editorCursor(e, o.outputWidth, o.cursorColumn)
+
+	row, col = o.cursorRow, o.cursorColumn
+	for i, paragraph := range e.Paragraphs() {
+		if i > 0 {
+			o.nextLine()
+		}
+
+		if i != cursorParagraph {
+			o.writeStyle(paragraph, style)
+			continue
+		}
+
+		beforeCursor := firstGraphemes(paragraph, cursorOffset)
+		o.writeStyle(beforeCursor, style)
+		row, col = o.cursorRow, o.cursorColumn
+		o.writeStyle(paragraph[len(beforeCursor):], style)
+	}
+
+	// The cursor goes to the start of the next row once a row is full.
+	if col >= o.outputWidth {
+		row, col = row+1, 0
+	}
+
+	return row, col
+}
+
+// editorCursor returns the paragraph that the editor's cursor is in and the number of grapheme clusters before it in
+// that paragraph. The editor only reports the row and column of the cursor within its own lines.
+func editorCursor(e *editor.TextEditor, width, firstLineIndent int) (paragraph, offset int) {
+	cursorRow, cursorColumn := e.CursorRow(), e.CursorColumn()
+	paragraphs := e.Paragraphs()
+
+	startRow := 0
+	for i, text := range paragraphs {
+		indent := 0
+		if i == 0 {
+			indent = firstLineIndent
+		}
+
+		numRows := max(1, (uniseg.GraphemeClusterCount(text)+indent+width-1)/width)
+
+		// The end of a paragraph that fills its last line is reported at the start of the row after it, the same as the
+		// start of the next paragraph.
+		isAtEndOfFullParagraph := cursorRow == startRow+numRows && cursorColumn == 0 && !e.CursorIsAtStartOfParagraph()
+		if cursorRow < startRow+numRows || isAtEndOfFullParagraph || i == len(paragraphs)-1 {
+			return i, (cursorRow-startRow)*width + cursorColumn - indent
+		}
+
+		startRow += numRows
+	}
+
+	return 0, 0
+}
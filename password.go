@@ -0,0 +1,333 @@
+package prompt
+
+import (
+	"context"
+	editor "github.com/JosephNaberhaus/texteditor"
+	"github.com/rivo/uniseg"
+	"strings"
+)
+
+const (
+	defaultMaskRune        = '*'
+	defaultConfirmQuestion = "Confirm password"
+
+	// Shown once the prompt is finished so that the length of the password isn't revealed
+	passwordPlaceholder = "[hidden]"
+)
+
+// Password asks for a secret without echoing it. The secret is never written to the terminal unless the user presses
+// the reveal key, and the finished prompt shows a fixed placeholder instead of the secret.
+type Password struct {
+	base
+
+	// A stable identifier for the prompt. When it is set, the AnswerProvider is asked for a response before the prompt
+	// is shown to the user.
+	ID string
+
+	// Supplies responses to skip the prompt. Defaults to DefaultAnswerProvider.
+	AnswerProvider AnswerProvider
+
+	// The question to display to the user
+	Question string
+
+	// The rune displayed in place of each character of the input. Defaults to '*'.
+	MaskRune rune
+
+	// Whether to display nothing at all while the user types, rather than a mask rune per character
+	ShouldHideInput bool
+
+	// Validates the current input. Return an empty string when the input is valid and return a message to display to
+	// the user when it is not valid.
+	ValidatorFunc func(string) string
+
+	// Whether the user has to type the password a second time to confirm it
+	ShouldConfirm bool
+
+	// The question to display when asking the user to confirm the password
+	// Default is "Confirm password"
+	ConfirmQuestion string
+
+	// The key that toggles between masking and revealing the input
+	// Default is Ctrl-R
+	RevealKey Key
+
+	// Whether the user should be prevented from revealing the input
+	ShouldPreventReveal bool
+
+	// Called when a key is pressed but before it is processed. Return `false` to cancel the event.
+	OnKeyFunc func(Prompt, Key) bool
+
+	// The terminal to show the prompt on. Defaults to DefaultTerminal.
+	Terminal Terminal
+
+	// The symbols and colors to draw the prompt with. Defaults to DefaultTheme.
+	Theme *Theme
+
+	isRevealed       bool
+	isConfirming     bool
+	didAttemptSubmit bool
+	errorMessage     string
+	firstEntry       string
+
+	editor *editor.TextEditor
+}
+
+// Show displays the prompt to the user and blocks the current Go routine until the user submits
+func (p *Password) Show() error {
+	return p.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (p *Password) ShowContext(ctx context.Context) error {
+	err := p.show(ctx, p.Terminal, p.Theme)
+	if err != nil {
+		return err
+	}
+	defer p.restoreAfterPanic()
+
+	answer, hasAnswer, err := p.lookupAnswer(p.ID, p.AnswerProvider)
+	if err != nil || hasAnswer {
+		if err == nil {
+			err = p.setResponse(answer)
+		}
+
+		return p.finishWithResponse(err, p.Question, p.render, p.responseText)
+	}
+
+	if p.isLineMode() {
+		return p.showLines(ctx)
+	}
+
+	if p.editor == nil {
+		p.editor = editor.NewEditor()
+		p.editor.SetWidth(p.output.outputWidth)
+	}
+
+	p.resizeFunc = p.resize
+	p.isInputEmptyFunc = func() bool { return p.editor.String() == "" }
+	p.hideInputFunc = p.hideInput
+	p.render(false)
+
+	for p.State() == Showing {
+		nextKey, err := p.nextKey(ctx)
+		if err != nil {
+			return p.abort(ctx, err)
+		}
+
+		p.handleInput(nextKey)
+	}
+
+	return p.closeErr
+}
+
+// resize wraps the input to the new width of the terminal and redraws the prompt.
+func (p *Password) resize() {
+	p.editor.SetWidth(p.output.outputWidth)
+	p.render(false)
+}
+
+func (p *Password) handleInput(input Key) {
+	if p.State() != Showing {
+		return
+	}
+
+	if p.OnKeyFunc != nil && !p.OnKeyFunc(p, input) {
+		return
+	}
+
+	p.didAttemptSubmit = false
+
+	if !p.ShouldPreventReveal && input == p.revealKey() {
+		p.isRevealed = !p.isRevealed
+	} else if input == ControlEnter {
+		p.submit()
+	} else if input != ControlUp && input != ControlDown {
+		p.errorMessage = ""
+		applyKeyToEditor(input, p.editor)
+	}
+
+	if p.State() == Showing {
+		p.render(false)
+	}
+}
+
+// submit moves on to the confirmation step or finishes the prompt once the input is valid.
+func (p *Password) submit() {
+	p.didAttemptSubmit = true
+
+	if !p.isConfirming {
+		p.errorMessage = p.validate()
+		if p.errorMessage != "" {
+			return
+		}
+
+		if p.ShouldConfirm {
+			p.firstEntry = p.input()
+			p.isConfirming = true
+			p.isRevealed = false
+			p.editor = editor.NewEditor()
+			p.editor.SetWidth(p.output.outputWidth)
+			return
+		}
+	} else if p.input() != p.firstEntry {
+		p.errorMessage = "passwords don't match"
+		p.firstEntry = ""
+		p.isConfirming = false
+		p.isRevealed = false
+		p.editor = editor.NewEditor()
+		p.editor.SetWidth(p.output.outputWidth)
+		return
+	}
+
+	p.firstEntry = ""
+	p.render(true)
+	p.finish()
+}
+
+// showLines reads the response without the interactive prompt. When confirmation is required the next line has to
+// repeat the password.
+func (p *Password) showLines(ctx context.Context) error {
+	line, err := p.readLine(ctx, p.Question)
+
+	if err == nil && p.ShouldConfirm {
+		var confirmation string
+		confirmation, err = p.readLine(ctx, p.Question)
+		if err == nil && confirmation != line {
+			err = &ValidationError{Message: "passwords don't match"}
+		}
+	}
+
+	if err == nil {
+		err = p.setResponse(line)
+	}
+
+	return p.finishWithResponse(err, p.Question, p.render, p.responseText)
+}
+
+// setResponse replaces the input with the response and validates it.
+func (p *Password) setResponse(response string) error {
+	p.editor = editor.NewEditor()
+	if p.output != nil {
+		p.editor.SetWidth(p.output.outputWidth)
+	}
+	p.editor.Write(response)
+
+	message := p.validate()
+	if message != "" {
+		return &ValidationError{Message: stripMarkup(message)}
+	}
+
+	return nil
+}
+
+func (p *Password) render(isFinished bool) {
+	p.output.clear()
+
+	if p.isConfirming {
+		p.writeQuestion(p.confirmQuestion())
+	} else {
+		p.writeQuestion(p.Question)
+	}
+	p.output.write(": ")
+
+	if isFinished {
+		p.writeAnswer(p.responseText())
+		return
+	}
+
+	p.editor.SetFirstLineIndent(p.output.cursorColumn)
+
+	cursorRow, cursorColumn := p.output.cursorRow, p.output.cursorColumn
+	if p.isRevealed {
+		cursorRow, cursorColumn = p.output.writeEditor(p.editor, Style{})
+	} else if !p.ShouldHideInput {
+		cursorRow, cursorColumn = p.writeMask()
+	}
+
+	if p.didAttemptSubmit && p.errorMessage != "" {
+		p.output.nextLine()
+		p.writeError(p.errorMessage)
+	}
+
+	p.output.setCursor(cursorRow, cursorColumn)
+	p.output.flush()
+}
+
+// hideInput draws the placeholder in place of the input, which hides its length as well when it was masked.
+func (p *Password) hideInput() {
+	p.isRevealed = false
+	p.render(true)
+}
+
+// writeMask writes a mask rune for each grapheme cluster of the input and returns where the cursor is among them, in
+// the same way as writeEditor.
+func (p *Password) writeMask() (row, col int) {
+	cursorParagraph, numBeforeCursor := editorCursor(p.editor, p.output.outputWidth, p.output.cursorColumn)
+	paragraphs := p.editor.Paragraphs()
+	for _, paragraph := range paragraphs[:cursorParagraph] {
+		numBeforeCursor += uniseg.GraphemeClusterCount(paragraph)
+	}
+
+	mask := string(p.maskRune())
+	p.output.write(strings.Repeat(mask, numBeforeCursor))
+	row, col = p.output.cursorRow, p.output.cursorColumn
+	p.output.write(strings.Repeat(mask, p.editor.NumGraphemes()-numBeforeCursor))
+
+	// The cursor goes to the start of the next row once a row is full.
+	if col >= p.output.outputWidth {
+		row, col = row+1, 0
+	}
+
+	return row, col
+}
+
+// responseText returns the placeholder that is shown instead of the response.
+func (p *Password) responseText() string {
+	return passwordPlaceholder
+}
+
+func (p *Password) maskRune() rune {
+	if p.MaskRune == 0 {
+		return defaultMaskRune
+	}
+
+	return p.MaskRune
+}
+
+func (p *Password) revealKey() Key {
+	if p.RevealKey == nil {
+		return ControlCtrlR
+	}
+
+	return p.RevealKey
+}
+
+func (p *Password) confirmQuestion() string {
+	if p.ConfirmQuestion == "" {
+		return defaultConfirmQuestion
+	}
+
+	return p.ConfirmQuestion
+}
+
+func (p *Password) validate() string {
+	if p.ValidatorFunc != nil {
+		return p.ValidatorFunc(p.input())
+	}
+
+	return ""
+}
+
+func (p *Password) input() string {
+	return strings.Join(p.editor.Paragraphs(), "")
+}
+
+// Response returns the password entered by the user.
+func (p *Password) Response() string {
+	if p.editor == nil {
+		return ""
+	}
+
+	return p.input()
+}
@@ -0,0 +1,132 @@
+package prompt_test
+
+import (
+	"errors"
+	"github.com/JosephNaberhaus/prompt"
+	"github.com/JosephNaberhaus/prompt/prompttest"
+	"testing"
+)
+
+func TestPasswordMaskCursor(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		keys      []prompt.Key
+		expected  string
+		cursorRow int
+		cursorCol int
+	}{
+		{
+			name:      "after the question",
+			width:     40,
+			keys:      prompttest.Type("secret"),
+			expected:  "? Enter your database password: ******",
+			cursorRow: 0,
+			cursorCol: 38,
+		},
+		{
+			name:      "after a wrapped question",
+			width:     22,
+			keys:      prompttest.Type("secret"),
+			expected:  "? Enter your database\n  password: ******",
+			cursorRow: 1,
+			cursorCol: 18,
+		},
+		{
+			name:      "moved within the mask",
+			width:     22,
+			keys:      append(prompttest.Type("secret"), prompt.ControlLeft, prompt.ControlLeft),
+			expected:  "? Enter your database\n  password: ******",
+			cursorRow: 1,
+			cursorCol: 16,
+		},
+		{
+			name:      "mask filling the row",
+			width:     22,
+			keys:      prompttest.Type("secret1234"),
+			expected:  "? Enter your database\n  password: **********",
+			cursorRow: 2,
+			cursorCol: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terminal := prompttest.NewTerminal(test.width, 10, test.keys...)
+			terminal.Press(prompt.Noop, prompt.ControlEnter)
+
+			var shown snapshot
+			p := prompt.Password{
+				Question:  "Enter your database password",
+				Terminal:  terminal,
+				OnKeyFunc: snapshotOnNoop(terminal, &shown),
+			}
+
+			err := p.Show()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertShown(t, shown, test.expected)
+			if shown.row != test.cursorRow || shown.col != test.cursorCol {
+				t.Errorf("expected the cursor at (%d, %d) but it was at (%d, %d)", test.cursorRow, test.cursorCol, shown.row, shown.col)
+			}
+		})
+	}
+}
+
+func TestPasswordHiddenWhenStopped(t *testing.T) {
+	tests := []struct {
+		name        string
+		keys        []prompt.Key
+		expectedErr error
+	}{
+		{name: "interrupted while masked", keys: append(prompttest.Type("hunter2"), prompt.ControlCtrlC), expectedErr: prompt.ErrInterrupted},
+		{name: "interrupted while revealed", keys: append(prompttest.Type("hunter2"), prompt.ControlCtrlR, prompt.ControlCtrlC), expectedErr: prompt.ErrInterrupted},
+		{name: "input ended while revealed", keys: append(prompttest.Type("hunter2"), prompt.ControlCtrlR), expectedErr: prompttest.ErrScriptEnded},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terminal := prompttest.NewTerminal(40, 10, test.keys...)
+			p := prompt.Password{
+				Question: "Token",
+				Terminal: terminal,
+			}
+
+			err := p.Show()
+			if !errors.Is(err, test.expectedErr) {
+				t.Fatalf("expected %v but got %v", test.expectedErr, err)
+			}
+
+			prompttest.AssertScreen(t, terminal.Screen, "? Token: [hidden]")
+		})
+	}
+}
+
+func TestPasswordHiddenAfterPanic(t *testing.T) {
+	terminal := prompttest.NewTerminal(40, 10, append(prompttest.Type("hunter2"), prompt.ControlCtrlR, prompt.Noop)...)
+	p := prompt.Password{
+		Question: "Token",
+		Terminal: terminal,
+		OnKeyFunc: func(_ prompt.Prompt, key prompt.Key) bool {
+			if key == prompt.Noop {
+				panic("callback failed")
+			}
+
+			return true
+		},
+	}
+
+	func() {
+		defer func() {
+			if recover() == nil {
+				t.Fatal("expected the panic to carry on")
+			}
+		}()
+
+		p.Show()
+	}()
+
+	prompttest.AssertScreen(t, terminal.Screen, "? Token: [hidden]")
+}
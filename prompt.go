@@ -1,7 +1,10 @@
 package prompt
 
+import "context"
+
 type Prompt interface {
 	Show() error
+	ShowContext(ctx context.Context) error
 	Pause() error
 	ResetToWaiting() error
 	State() State
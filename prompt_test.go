@@ -0,0 +1,36 @@
+package prompt_test
+
+import (
+	"github.com/JosephNaberhaus/prompt"
+	"github.com/JosephNaberhaus/prompt/prompttest"
+	"testing"
+)
+
+// snapshot is the screen as it looked while a prompt was being shown.
+type snapshot struct {
+	screen   string
+	row, col int
+}
+
+// snapshotOnNoop returns an OnKeyFunc that records the screen and cursor each time prompt.Noop is read. Place Noop in a
+// key script to look at the prompt before it finishes.
+func snapshotOnNoop(terminal *prompttest.Terminal, s *snapshot) func(prompt.Prompt, prompt.Key) bool {
+	return func(_ prompt.Prompt, key prompt.Key) bool {
+		if key != prompt.Noop {
+			return true
+		}
+
+		s.screen = terminal.Screen.String()
+		s.row, s.col = terminal.Screen.Cursor()
+		return false
+	}
+}
+
+// assertShown fails the test if the screen in the snapshot doesn't equal expected.
+func assertShown(t *testing.T, shown snapshot, expected string) {
+	t.Helper()
+
+	if shown.screen != expected {
+		t.Errorf("unexpected screen\n--- expected ---\n%s\n--- actual ---\n%s", expected, shown.screen)
+	}
+}
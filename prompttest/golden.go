@@ -0,0 +1,65 @@
+package prompttest
+
+import (
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+)
+
+var update = flag.Bool("prompttest.update", false, "rewrite golden files with the actual output")
+
+// AssertScreen fails the test if the visible text of the screen doesn't equal expected.
+func AssertScreen(t testing.TB, screen *Screen, expected string) {
+	t.Helper()
+
+	actual := screen.String()
+	if actual != expected {
+		t.Errorf("unexpected screen\n--- expected ---\n%s\n--- actual ---\n%s", expected, actual)
+	}
+}
+
+// AssertGolden compares actual with the contents of testdata/<name>.golden and fails the test if they differ. Run the
+// tests with -prompttest.update to write actual to the file instead.
+func AssertGolden(t testing.TB, name string, actual string) {
+	t.Helper()
+
+	path := filepath.Join("testdata", name+".golden")
+
+	if *update {
+		err := os.MkdirAll(filepath.Dir(path), 0755)
+		if err != nil {
+			t.Fatalf("couldn't create golden file directory: %v", err)
+		}
+
+		err = os.WriteFile(path, []byte(actual), 0644)
+		if err != nil {
+			t.Fatalf("couldn't write golden file: %v", err)
+		}
+
+		return
+	}
+
+	expected, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatalf("couldn't read golden file (run with -prompttest.update to create it): %v", err)
+	}
+
+	if string(expected) != actual {
+		t.Errorf("output doesn't match %s\n--- expected ---\n%s\n--- actual ---\n%s", path, expected, actual)
+	}
+}
+
+// AssertGoldenFrames compares every frame rendered on the terminal with testdata/<name>.golden.
+func AssertGoldenFrames(t testing.TB, name string, terminal *Terminal) {
+	t.Helper()
+
+	sb := strings.Builder{}
+	for i, frame := range terminal.Frames() {
+		fmt.Fprintf(&sb, "--- frame %d ---\n%s\n", i, frame)
+	}
+
+	AssertGolden(t, name, sb.String())
+}
@@ -0,0 +1,541 @@
+package prompttest
+
+import (
+	"github.com/rivo/uniseg"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+)
+
+// Screen is an in-memory emulator for the subset of VT100/xterm escape sequences that prompts emit. Newlines are
+// treated as a carriage return followed by a line feed, the same way a terminal with output post-processing does. Like
+// most modern terminals, rows that were wrapped are reflowed when the screen is resized.
+type Screen struct {
+	width, height int
+
+	// Each cell holds one grapheme cluster. The cell after a double-width cluster holds an empty string.
+	cells [][]string
+
+	// Whether each row was started by text wrapping past the end of the previous row.
+	wrapped []bool
+
+	scrollback []string
+
+	row, col           int
+	savedRow, savedCol int
+	pendingWrap        bool
+	cursorHidden       bool
+
+	// Bytes of an escape sequence or UTF-8 character that was split across writes.
+	incomplete []byte
+}
+
+// NewScreen creates a blank screen with the given number of columns and rows.
+func NewScreen(width, height int) *Screen {
+	s := &Screen{width: width, height: height}
+	s.cells = make([][]string, height)
+	for i := range s.cells {
+		s.cells[i] = s.blankRow()
+	}
+	s.wrapped = make([]bool, height)
+
+	return s
+}
+
+// Write interprets the bytes as terminal output. It never returns an error.
+func (s *Screen) Write(p []byte) (int, error) {
+	data := append(s.incomplete, p...)
+	s.incomplete = nil
+
+	for len(data) > 0 {
+		n := s.consume(data)
+		if n == 0 {
+			s.incomplete = append([]byte(nil), data...)
+			break
+		}
+
+		data = data[n:]
+	}
+
+	return len(p), nil
+}
+
+// Width returns the number of columns on the screen.
+func (s *Screen) Width() int {
+	return s.width
+}
+
+// Height returns the number of rows on the screen.
+func (s *Screen) Height() int {
+	return s.height
+}
+
+// Line returns the visible text of a row with trailing spaces removed.
+func (s *Screen) Line(row int) string {
+	sb := strings.Builder{}
+	for _, cell := range s.cells[row] {
+		sb.WriteString(cell)
+	}
+
+	return strings.TrimRight(sb.String(), " ")
+}
+
+// Lines returns the visible text of every row with trailing blank rows removed.
+func (s *Screen) Lines() []string {
+	lines := make([]string, s.height)
+	for row := range lines {
+		lines[row] = s.Line(row)
+	}
+
+	for len(lines) > 0 && lines[len(lines)-1] == "" {
+		lines = lines[:len(lines)-1]
+	}
+
+	return lines
+}
+
+// String returns the visible text of the screen with each row on its own line.
+func (s *Screen) String() string {
+	return strings.Join(s.Lines(), "\n")
+}
+
+// Scrollback returns the rows that have been scrolled off the top of the screen, oldest first.
+func (s *Screen) Scrollback() []string {
+	return s.scrollback
+}
+
+// Cursor returns the zero-based position of the cursor.
+func (s *Screen) Cursor() (row, col int) {
+	return s.row, s.col
+}
+
+// CursorVisible returns whether the cursor has been hidden with an escape sequence.
+func (s *Screen) CursorVisible() bool {
+	return !s.cursorHidden
+}
+
+// Resize changes the dimensions of the screen. Rows that were wrapped are joined back together and wrapped again at the
+// new width. Rows above the cursor that no longer fit are moved into the scrollback, keeping the cursor on the screen.
+func (s *Screen) Resize(width, height int) {
+	type logicalLine struct {
+		cells []string
+	}
+
+	cursorCol := s.col
+	if s.pendingWrap {
+		cursorCol++
+	}
+
+	// Join the wrapped rows back into the lines that were written.
+	var lines []logicalLine
+	cursorLine, cursorOffset := 0, 0
+	for row := range s.cells {
+		if row == 0 || !s.wrapped[row] {
+			lines = append(lines, logicalLine{})
+		}
+
+		current := &lines[len(lines)-1]
+		if row == s.row {
+			cursorLine, cursorOffset = len(lines)-1, len(current.cells)+cursorCol
+		}
+
+		cells := s.cells[row]
+		if row+1 >= s.height || !s.wrapped[row+1] {
+			cells = trimBlankCells(cells)
+		}
+		current.cells = append(current.cells, cells...)
+	}
+
+	// Wrap each line at the new width.
+	var rows [][]string
+	var wrapped []bool
+	cursorRow, cursorColumn := 0, 0
+	for i, line := range lines {
+		start := len(rows)
+		rows = append(rows, nil)
+		wrapped = append(wrapped, false)
+
+		for offset, cell := range line.cells {
+			cellWidth := 1
+			if offset+1 < len(line.cells) && line.cells[offset+1] == "" {
+				cellWidth = 2
+			}
+
+			if cell != "" && len(rows[len(rows)-1])+cellWidth > width {
+				rows = append(rows, nil)
+				wrapped = append(wrapped, true)
+			}
+
+			if i == cursorLine && offset == cursorOffset {
+				cursorRow, cursorColumn = len(rows)-1, len(rows[len(rows)-1])
+			}
+
+			rows[len(rows)-1] = append(rows[len(rows)-1], cell)
+		}
+
+		if i == cursorLine && cursorOffset >= len(line.cells) {
+			cursorRow = len(rows) - 1
+			cursorColumn = len(rows[cursorRow]) + cursorOffset - len(line.cells)
+			if cursorOffset-len(line.cells) > 0 {
+				cursorRow = start + cursorOffset/width
+				cursorColumn = cursorOffset % width
+			}
+		}
+	}
+
+	// Drop blank rows below the cursor and push rows that don't fit into the scrollback.
+	for len(rows) > cursorRow+1 && len(trimBlankCells(rows[len(rows)-1])) == 0 {
+		rows = rows[:len(rows)-1]
+		wrapped = wrapped[:len(wrapped)-1]
+	}
+
+	s.width, s.height = width, height
+	for len(rows) > height && cursorRow >= height {
+		s.scrollback = append(s.scrollback, strings.TrimRight(strings.Join(rows[0], ""), " "))
+		rows = rows[1:]
+		wrapped = wrapped[1:]
+		cursorRow--
+	}
+
+	// Rows below the cursor that still don't fit are lost, like they are on most terminals.
+	if len(rows) > height {
+		rows = rows[:height]
+		wrapped = wrapped[:height]
+	}
+
+	s.cells = make([][]string, height)
+	s.wrapped = make([]bool, height)
+	for row := range s.cells {
+		s.cells[row] = s.blankRow()
+		if row < len(rows) {
+			copy(s.cells[row], rows[row])
+			s.wrapped[row] = wrapped[row]
+		}
+	}
+
+	s.pendingWrap = false
+	s.moveTo(cursorRow, cursorColumn)
+}
+
+// trimBlankCells removes the blank cells from the end of a row.
+func trimBlankCells(cells []string) []string {
+	end := len(cells)
+	for end > 0 && cells[end-1] == " " {
+		end--
+	}
+
+	return cells[:end]
+}
+
+func (s *Screen) blankRow() []string {
+	row := make([]string, s.width)
+	for i := range row {
+		row[i] = " "
+	}
+
+	return row
+}
+
+// consume interprets the start of data and returns how many bytes were used. Zero means that data is incomplete.
+func (s *Screen) consume(data []byte) int {
+	switch data[0] {
+	case '\033':
+		return s.consumeEscape(data)
+	case '\n':
+		s.col = 0
+		s.lineFeed()
+		s.wrapped[s.row] = false
+		return 1
+	case '\r':
+		s.col = 0
+		s.pendingWrap = false
+		return 1
+	case '\b':
+		s.moveTo(s.row, s.col-1)
+		return 1
+	case '\t':
+		s.moveTo(s.row, (s.col/8+1)*8)
+		return 1
+	}
+
+	if data[0] < ' ' || data[0] == 0x7F {
+		return 1
+	}
+
+	if !utf8.FullRune(data) {
+		return 0
+	}
+
+	r, n := utf8.DecodeRune(data)
+	s.writeRune(r)
+	return n
+}
+
+func (s *Screen) writeRune(r rune) {
+	// Runes that continue the previous grapheme cluster, such as combining marks, variation selectors and the parts of
+	// a joined emoji, are drawn in the same cell.
+	if row, col, ok := s.previousCellPosition(); ok && uniseg.GraphemeClusterCount(s.cells[row][col]+string(r)) == 1 {
+		s.extendCell(row, col, r)
+		return
+	}
+
+	width := uniseg.StringWidth(string(r))
+	if width == 0 {
+		return
+	}
+
+	if s.pendingWrap || s.col+width > s.width {
+		s.col = 0
+		s.lineFeed()
+		s.wrapped[s.row] = true
+	}
+
+	s.clearCell(s.row, s.col)
+	s.cells[s.row][s.col] = string(r)
+	if width == 2 {
+		s.clearCell(s.row, s.col+1)
+		s.cells[s.row][s.col+1] = ""
+	}
+
+	s.col += width
+	if s.col >= s.width {
+		s.col = s.width - 1
+		s.pendingWrap = true
+	}
+}
+
+// clearCell blanks a cell along with the other half of any double-width cluster that it is part of.
+func (s *Screen) clearCell(row, col int) {
+	if s.cells[row][col] == "" && col > 0 {
+		s.cells[row][col-1] = " "
+	} else if col+1 < s.width && s.cells[row][col+1] == "" {
+		s.cells[row][col+1] = " "
+	}
+
+	s.cells[row][col] = " "
+}
+
+func (s *Screen) previousCellPosition() (int, int, bool) {
+	row, col := s.row, s.col-1
+	if s.pendingWrap {
+		col = s.col
+	}
+
+	for col >= 0 && s.cells[row][col] == "" {
+		col--
+	}
+
+	return row, col, col >= 0
+}
+
+// extendCell adds a rune to the cluster in a cell. The cell is widened when the cluster becomes double-width, such as
+// when an emoji presentation selector follows a symbol.
+func (s *Screen) extendCell(row, col int, r rune) {
+	s.cells[row][col] += string(r)
+
+	isCursorAfterCell := row == s.row && col+1 == s.col && !s.pendingWrap
+	if !isCursorAfterCell || s.cells[row][col+1] == "" || uniseg.StringWidth(s.cells[row][col]) < 2 {
+		return
+	}
+
+	s.clearCell(row, col+1)
+	s.cells[row][col+1] = ""
+
+	s.col = col + 2
+	if s.col >= s.width {
+		s.col = s.width - 1
+		s.pendingWrap = true
+	}
+}
+
+func (s *Screen) lineFeed() {
+	s.pendingWrap = false
+
+	if s.row < s.height-1 {
+		s.row++
+		return
+	}
+
+	s.scrollUp(1)
+}
+
+func (s *Screen) scrollUp(n int) {
+	for i := 0; i < n; i++ {
+		s.scrollback = append(s.scrollback, s.Line(0))
+		s.cells = append(s.cells[1:], s.blankRow())
+		s.wrapped = append(s.wrapped[1:], false)
+	}
+}
+
+func (s *Screen) scrollDown(n int) {
+	for i := 0; i < n; i++ {
+		s.cells = append([][]string{s.blankRow()}, s.cells[:s.height-1]...)
+		s.wrapped = append([]bool{false}, s.wrapped[:s.height-1]...)
+	}
+}
+
+func (s *Screen) moveTo(row, col int) {
+	s.row = max(0, min(row, s.height-1))
+	s.col = max(0, min(col, s.width-1))
+	s.pendingWrap = false
+}
+
+func (s *Screen) consumeEscape(data []byte) int {
+	if len(data) < 2 {
+		return 0
+	}
+
+	switch data[1] {
+	case '[':
+		return s.consumeCSI(data)
+	case ']':
+		return consumeOSC(data)
+	case '7':
+		s.savedRow, s.savedCol = s.row, s.col
+		return 2
+	case '8':
+		s.moveTo(s.savedRow, s.savedCol)
+		return 2
+	case 'c':
+		*s = *NewScreen(s.width, s.height)
+		return 2
+	}
+
+	return 2
+}
+
+// consumeOSC skips operating system commands such as hyperlinks and window titles.
+func consumeOSC(data []byte) int {
+	for i := 2; i < len(data); i++ {
+		if data[i] == '\a' {
+			return i + 1
+		}
+
+		if data[i] == '\033' {
+			if i+1 == len(data) {
+				return 0
+			}
+
+			return i + 2
+		}
+	}
+
+	return 0
+}
+
+func (s *Screen) consumeCSI(data []byte) int {
+	end := 2
+	for end < len(data) && (data[end] < 0x40 || data[end] > 0x7E) {
+		end++
+	}
+
+	if end == len(data) {
+		return 0
+	}
+
+	rawParams := string(data[2:end])
+	isPrivate := strings.HasPrefix(rawParams, "?")
+	params := parseParams(strings.TrimPrefix(rawParams, "?"))
+
+	param := func(i, defaultValue int) int {
+		if i >= len(params) || params[i] == 0 {
+			return defaultValue
+		}
+
+		return params[i]
+	}
+
+	switch data[end] {
+	case 'A':
+		s.moveTo(s.row-param(0, 1), s.col)
+	case 'B':
+		s.moveTo(s.row+param(0, 1), s.col)
+	case 'C':
+		s.moveTo(s.row, s.col+param(0, 1))
+	case 'D':
+		s.moveTo(s.row, s.col-param(0, 1))
+	case 'E':
+		s.moveTo(s.row+param(0, 1), 0)
+	case 'F':
+		s.moveTo(s.row-param(0, 1), 0)
+	case 'G':
+		s.moveTo(s.row, param(0, 1)-1)
+	case 'd':
+		s.moveTo(param(0, 1)-1, s.col)
+	case 'H', 'f':
+		s.moveTo(param(0, 1)-1, param(1, 1)-1)
+	case 'K':
+		s.eraseLine(param(0, 0))
+	case 'J':
+		s.eraseDisplay(param(0, 0))
+	case 'S':
+		s.scrollUp(param(0, 1))
+	case 'T':
+		s.scrollDown(param(0, 1))
+	case 's':
+		s.savedRow, s.savedCol = s.row, s.col
+	case 'u':
+		s.moveTo(s.savedRow, s.savedCol)
+	case 'h', 'l':
+		if isPrivate && param(0, 0) == 25 {
+			s.cursorHidden = data[end] == 'l'
+		}
+	}
+
+	// Anything else, such as colors, doesn't affect the text on the screen.
+	return end + 1
+}
+
+func parseParams(raw string) []int {
+	if raw == "" {
+		return nil
+	}
+
+	parts := strings.Split(raw, ";")
+	params := make([]int, len(parts))
+	for i, part := range parts {
+		params[i], _ = strconv.Atoi(part)
+	}
+
+	return params
+}
+
+func (s *Screen) eraseLine(mode int) {
+	from, to := 0, s.width
+	switch mode {
+	case 0:
+		from = s.col
+	case 1:
+		to = s.col + 1
+	}
+
+	for col := from; col < to; col++ {
+		s.clearCell(s.row, col)
+	}
+
+	if mode == 2 {
+		s.wrapped[s.row] = false
+	}
+}
+
+func (s *Screen) eraseDisplay(mode int) {
+	switch mode {
+	case 0:
+		s.eraseLine(0)
+		for row := s.row + 1; row < s.height; row++ {
+			s.cells[row] = s.blankRow()
+			s.wrapped[row] = false
+		}
+	case 1:
+		s.eraseLine(1)
+		for row := 0; row < s.row; row++ {
+			s.cells[row] = s.blankRow()
+			s.wrapped[row] = false
+		}
+	default:
+		for row := range s.cells {
+			s.cells[row] = s.blankRow()
+			s.wrapped[row] = false
+		}
+	}
+}
@@ -0,0 +1,375 @@
+package prompttest
+
+import (
+	"strings"
+	"testing"
+)
+
+func TestScreenWrite(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		height    int
+		output    string
+		expected  string
+		cursorRow int
+		cursorCol int
+	}{
+		{
+			name:      "newlines return to the first column",
+			width:     10,
+			height:    3,
+			output:    "one\ntwo",
+			expected:  "one\ntwo",
+			cursorRow: 1,
+			cursorCol: 3,
+		},
+		{
+			name:      "carriage return overwrites the row",
+			width:     10,
+			height:    3,
+			output:    "hello\rJ",
+			expected:  "Jello",
+			cursorRow: 0,
+			cursorCol: 1,
+		},
+		{
+			name:      "relative cursor moves",
+			width:     10,
+			height:    4,
+			output:    "abc\x1b[2Bd\x1b[Ae\x1b[4Df\x1b[2Cg",
+			expected:  "abc\n f  g\n   d",
+			cursorRow: 1,
+			cursorCol: 5,
+		},
+		{
+			name:      "absolute cursor moves",
+			width:     10,
+			height:    4,
+			output:    "\x1b[3;4Ha\x1b[Hb\x1b[6Gc\x1b[2dd\x1b[Ee\x1b[2Ff",
+			expected:  "f    c\n      d\ne  a",
+			cursorRow: 0,
+			cursorCol: 1,
+		},
+		{
+			name:      "cursor moves stop at the edges",
+			width:     5,
+			height:    3,
+			output:    "\x1b[9A\x1b[9Da\x1b[9B\x1b[9Cb",
+			expected:  "a\n\n    b",
+			cursorRow: 2,
+			cursorCol: 4,
+		},
+		{
+			name:      "text wraps past the last column",
+			width:     5,
+			height:    3,
+			output:    "abcdefg",
+			expected:  "abcde\nfg",
+			cursorRow: 1,
+			cursorCol: 2,
+		},
+		{
+			name:      "cursor waits in the last column until the next character",
+			width:     5,
+			height:    3,
+			output:    "abcde",
+			expected:  "abcde",
+			cursorRow: 0,
+			cursorCol: 4,
+		},
+		{
+			name:      "erase to the end of the line",
+			width:     10,
+			height:    3,
+			output:    "abcdef\x1b[3G\x1b[K",
+			expected:  "ab",
+			cursorRow: 0,
+			cursorCol: 2,
+		},
+		{
+			name:      "erase to the start of the line",
+			width:     10,
+			height:    3,
+			output:    "abcdef\x1b[3G\x1b[1K",
+			expected:  "   def",
+			cursorRow: 0,
+			cursorCol: 2,
+		},
+		{
+			name:      "erase the whole line",
+			width:     10,
+			height:    3,
+			output:    "abc\ndef\x1b[2K",
+			expected:  "abc",
+			cursorRow: 1,
+			cursorCol: 3,
+		},
+		{
+			name:      "erase to the end of the screen",
+			width:     10,
+			height:    3,
+			output:    "abc\ndef\nghi\x1b[2;2H\x1b[J",
+			expected:  "abc\nd",
+			cursorRow: 1,
+			cursorCol: 1,
+		},
+		{
+			name:      "erase to the start of the screen",
+			width:     10,
+			height:    3,
+			output:    "abc\ndef\nghi\x1b[2;2H\x1b[1J",
+			expected:  "\n  f\nghi",
+			cursorRow: 1,
+			cursorCol: 1,
+		},
+		{
+			name:      "erase the whole screen",
+			width:     10,
+			height:    3,
+			output:    "abc\ndef\x1b[2J",
+			expected:  "",
+			cursorRow: 1,
+			cursorCol: 3,
+		},
+		{
+			name:      "save and restore with DEC sequences",
+			width:     10,
+			height:    3,
+			output:    "ab\x1b7\ncd\x1b8e",
+			expected:  "abe\ncd",
+			cursorRow: 0,
+			cursorCol: 3,
+		},
+		{
+			name:      "save and restore with CSI sequences",
+			width:     10,
+			height:    3,
+			output:    "ab\x1b[s\ncd\x1b[ue",
+			expected:  "abe\ncd",
+			cursorRow: 0,
+			cursorCol: 3,
+		},
+		{
+			name:      "wide characters take two cells",
+			width:     10,
+			height:    3,
+			output:    "日本a",
+			expected:  "日本a",
+			cursorRow: 0,
+			cursorCol: 5,
+		},
+		{
+			name:      "wide character that doesn't fit wraps",
+			width:     5,
+			height:    3,
+			output:    "ab日本",
+			expected:  "ab日\n本",
+			cursorRow: 1,
+			cursorCol: 2,
+		},
+		{
+			name:      "overwriting half of a wide character clears the other half",
+			width:     10,
+			height:    3,
+			output:    "日本\x1b[2Ga\x1b[4Gb",
+			expected:  " a b",
+			cursorRow: 0,
+			cursorCol: 4,
+		},
+		{
+			name:      "erasing half of a wide character clears all of it",
+			width:     10,
+			height:    3,
+			output:    "日本語\x1b[4G\x1b[K\x1b[1G\x1b[1K",
+			expected:  "",
+			cursorRow: 0,
+			cursorCol: 0,
+		},
+		{
+			name:      "combining marks join the previous cell",
+			width:     10,
+			height:    3,
+			output:    "éa",
+			expected:  "éa",
+			cursorRow: 0,
+			cursorCol: 2,
+		},
+		{
+			name:      "joined emoji take one double-width cell",
+			width:     10,
+			height:    3,
+			output:    "👩‍💻a",
+			expected:  "👩‍💻a",
+			cursorRow: 0,
+			cursorCol: 3,
+		},
+		{
+			name:      "colors and cursor visibility don't print",
+			width:     10,
+			height:    3,
+			output:    "\x1b[?25l\x1b[1;31mred\x1b[0m\x1b]8;;https://example.com\x1b\\link\x1b]8;;\x1b\\",
+			expected:  "redlink",
+			cursorRow: 0,
+			cursorCol: 7,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := NewScreen(test.width, test.height)
+			screen.Write([]byte(test.output))
+
+			AssertScreen(t, screen, test.expected)
+
+			row, col := screen.Cursor()
+			if row != test.cursorRow || col != test.cursorCol {
+				t.Errorf("expected the cursor at (%d, %d) but it was at (%d, %d)", test.cursorRow, test.cursorCol, row, col)
+			}
+		})
+	}
+}
+
+func TestScreenWriteSplitSequences(t *testing.T) {
+	screen := NewScreen(10, 3)
+
+	output := "a\x1b[31m日\x1b[2;3Hb"
+	for i := 0; i < len(output); i++ {
+		screen.Write([]byte{output[i]})
+	}
+
+	AssertScreen(t, screen, "a日\n  b")
+}
+
+func TestScreenScrollback(t *testing.T) {
+	screen := NewScreen(10, 2)
+	screen.Write([]byte("one\ntwo\nthree\nfour"))
+
+	AssertScreen(t, screen, "three\nfour")
+	assertScrollback(t, screen, "one", "two")
+
+	screen.Write([]byte("\x1b[S"))
+	AssertScreen(t, screen, "four")
+	assertScrollback(t, screen, "one", "two", "three")
+
+	screen.Write([]byte("\x1b[T"))
+	AssertScreen(t, screen, "\nfour")
+}
+
+func TestScreenResize(t *testing.T) {
+	tests := []struct {
+		name               string
+		width, height      int
+		output             string
+		newWidth           int
+		newHeight          int
+		expected           string
+		expectedScrollback []string
+		cursorRow          int
+		cursorCol          int
+	}{
+		{
+			name:      "wrapped rows are joined when widening",
+			width:     5,
+			height:    3,
+			output:    "abcdefgh",
+			newWidth:  10,
+			newHeight: 3,
+			expected:  "abcdefgh",
+			cursorRow: 0,
+			cursorCol: 8,
+		},
+		{
+			name:      "cursor waiting to wrap moves after the last character",
+			width:     5,
+			height:    3,
+			output:    "abcde",
+			newWidth:  10,
+			newHeight: 3,
+			expected:  "abcde",
+			cursorRow: 0,
+			cursorCol: 5,
+		},
+		{
+			name:      "rows are wrapped when narrowing",
+			width:     10,
+			height:    4,
+			output:    "abcdef\nxy",
+			newWidth:  4,
+			newHeight: 4,
+			expected:  "abcd\nef\nxy",
+			cursorRow: 2,
+			cursorCol: 2,
+		},
+		{
+			name:      "separate lines stay separate",
+			width:     3,
+			height:    3,
+			output:    "abc\nde",
+			newWidth:  10,
+			newHeight: 3,
+			expected:  "abc\nde",
+			cursorRow: 1,
+			cursorCol: 2,
+		},
+		{
+			name:      "wide characters wrap whole",
+			width:     10,
+			height:    3,
+			output:    "日本語",
+			newWidth:  5,
+			newHeight: 3,
+			expected:  "日本\n語",
+			cursorRow: 1,
+			cursorCol: 2,
+		},
+		{
+			name:               "rows that don't fit move into the scrollback",
+			width:              10,
+			height:             2,
+			output:             "one\ntwo",
+			newWidth:           2,
+			newHeight:          2,
+			expected:           "tw\no",
+			expectedScrollback: []string{"on", "e"},
+			cursorRow:          1,
+			cursorCol:          1,
+		},
+		{
+			name:      "blank rows below the cursor are dropped when shortening",
+			width:     10,
+			height:    4,
+			output:    "one\ntwo\x1b[H",
+			newWidth:  10,
+			newHeight: 1,
+			expected:  "one",
+			cursorRow: 0,
+			cursorCol: 0,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			screen := NewScreen(test.width, test.height)
+			screen.Write([]byte(test.output))
+			screen.Resize(test.newWidth, test.newHeight)
+
+			AssertScreen(t, screen, test.expected)
+			assertScrollback(t, screen, test.expectedScrollback...)
+
+			row, col := screen.Cursor()
+			if row != test.cursorRow || col != test.cursorCol {
+				t.Errorf("expected the cursor at (%d, %d) but it was at (%d, %d)", test.cursorRow, test.cursorCol, row, col)
+			}
+		})
+	}
+}
+
+func assertScrollback(t *testing.T, screen *Screen, expected ...string) {
+	t.Helper()
+
+	actual := screen.Scrollback()
+	if strings.Join(actual, "\n") != strings.Join(expected, "\n") || len(actual) != len(expected) {
+		t.Errorf("unexpected scrollback\n--- expected ---\n%s\n--- actual ---\n%s", strings.Join(expected, "\n"), strings.Join(actual, "\n"))
+	}
+}
@@ -0,0 +1,173 @@
+// Package prompttest runs prompts against a script of key presses and a virtual screen so that they can be tested
+// without a real terminal.
+package prompttest
+
+import (
+	"context"
+	"errors"
+	"github.com/JosephNaberhaus/prompt"
+)
+
+// ErrScriptEnded is returned by ReadKey once every key in the script has been read.
+var ErrScriptEnded = errors.New("prompttest: key script ended")
+
+type waitKey struct{}
+
+func (waitKey) IsText() bool {
+	return false
+}
+
+func (waitKey) Rune() rune {
+	return 0
+}
+
+// WaitForCancel can be placed in a script to make ReadKey block until its context is done. Use it to test what happens
+// when a prompt is cancelled or times out.
+var WaitForCancel prompt.Key = waitKey{}
+
+type resizeKey struct {
+	width, height int
+}
+
+func (resizeKey) IsText() bool {
+	return false
+}
+
+func (resizeKey) Rune() rune {
+	return 0
+}
+
+// ResizeTo can be placed in a script to resize the screen when it is read. The prompt is notified of the new size the
+// same way it would be by a real terminal.
+func ResizeTo(width, height int) prompt.Key {
+	return resizeKey{width: width, height: height}
+}
+
+// Terminal is a prompt.Terminal that plays back a script of keys and renders everything written to it on a Screen.
+type Terminal struct {
+	// The virtual screen that output is rendered on.
+	Screen *Screen
+
+	keys    []prompt.Key
+	frames  []string
+	isOpen  bool
+	resized chan struct{}
+}
+
+// NewTerminal creates a terminal with a blank screen of the given size that will read the given keys in order.
+func NewTerminal(width, height int, keys ...prompt.Key) *Terminal {
+	return &Terminal{
+		Screen:  NewScreen(width, height),
+		keys:    keys,
+		resized: make(chan struct{}, 1),
+	}
+}
+
+// Type converts text into the keys that typing it would produce. Newlines become ControlEnter.
+func Type(text string) []prompt.Key {
+	keys := make([]prompt.Key, 0, len(text))
+	for _, r := range text {
+		switch r {
+		case '\n':
+			keys = append(keys, prompt.ControlEnter)
+		case ' ':
+			keys = append(keys, prompt.ControlSpace)
+		default:
+			keys = append(keys, prompt.RuneKey(r))
+		}
+	}
+
+	return keys
+}
+
+// Press adds keys to the end of the script.
+func (t *Terminal) Press(keys ...prompt.Key) {
+	t.keys = append(t.keys, keys...)
+}
+
+// Remaining returns the keys in the script that haven't been read yet.
+func (t *Terminal) Remaining() []prompt.Key {
+	return t.keys
+}
+
+// Frames returns the screen as it looked after each write. Prompts write once per render that changes the screen, so
+// every frame is a snapshot of a single render.
+func (t *Terminal) Frames() []string {
+	return t.frames
+}
+
+// IsOpen returns whether the terminal has been opened by a prompt and not closed again.
+func (t *Terminal) IsOpen() bool {
+	return t.isOpen
+}
+
+// Resize reflows the screen to the new size and notifies the prompt that is being shown.
+func (t *Terminal) Resize(width, height int) {
+	t.Screen.Resize(width, height)
+	t.frames = append(t.frames, t.Screen.String())
+
+	select {
+	case t.resized <- struct{}{}:
+	default:
+	}
+}
+
+// Resized returns a channel that receives a value whenever Resize is called.
+func (t *Terminal) Resized() <-chan struct{} {
+	return t.resized
+}
+
+func (t *Terminal) Write(p []byte) (int, error) {
+	n, err := t.Screen.Write(p)
+	t.frames = append(t.frames, t.Screen.String())
+	return n, err
+}
+
+func (t *Terminal) Open() error {
+	if t.isOpen {
+		return errors.New("prompttest: terminal is already open")
+	}
+
+	t.isOpen = true
+	return nil
+}
+
+func (t *Terminal) Close() error {
+	if !t.isOpen {
+		return errors.New("prompttest: terminal is not open")
+	}
+
+	t.isOpen = false
+	return nil
+}
+
+func (t *Terminal) ReadKey(ctx context.Context) (prompt.Key, error) {
+	if err := ctx.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(t.keys) == 0 {
+		return nil, ErrScriptEnded
+	}
+
+	key := t.keys[0]
+	t.keys = t.keys[1:]
+
+	if key == WaitForCancel {
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	// The prompt cancels the read once it sees the notification, just like it would with a real terminal.
+	if resize, ok := key.(resizeKey); ok {
+		t.Resize(resize.width, resize.height)
+		<-ctx.Done()
+		return nil, ctx.Err()
+	}
+
+	return key, nil
+}
+
+func (t *Terminal) Size() (int, int, error) {
+	return t.Screen.Width(), t.Screen.Height(), nil
+}
@@ -0,0 +1,63 @@
+package prompt
+
+import (
+	"os"
+	"os/signal"
+)
+
+// ResizeNotifier is implemented by terminals that can tell when their size changes. Prompts shown on one lay
+// themselves out again and redraw whenever it is resized.
+type ResizeNotifier interface {
+	// Resized returns a channel that receives a value whenever the size of the terminal changes.
+	Resized() <-chan struct{}
+}
+
+// resizeWatcher turns resize signals into notifications while it is running.
+type resizeWatcher struct {
+	resized chan struct{}
+	signals chan os.Signal
+	stop    chan struct{}
+}
+
+func newResizeWatcher() *resizeWatcher {
+	return &resizeWatcher{resized: make(chan struct{}, 1)}
+}
+
+func (r *resizeWatcher) start() {
+	if len(resizeSignals) == 0 {
+		return
+	}
+
+	r.signals = make(chan os.Signal, 1)
+	r.stop = make(chan struct{})
+	signal.Notify(r.signals, resizeSignals...)
+
+	go func(signals <-chan os.Signal, stop <-chan struct{}) {
+		for {
+			select {
+			case <-signals:
+				r.notify()
+			case <-stop:
+				return
+			}
+		}
+	}(r.signals, r.stop)
+}
+
+func (r *resizeWatcher) halt() {
+	if r.signals == nil {
+		return
+	}
+
+	signal.Stop(r.signals)
+	close(r.stop)
+	r.signals = nil
+}
+
+// notify sends a notification unless one is already waiting to be received.
+func (r *resizeWatcher) notify() {
+	select {
+	case r.resized <- struct{}{}:
+	default:
+	}
+}
@@ -0,0 +1,10 @@
+//go:build !windows
+
+package prompt
+
+import (
+	"os"
+	"syscall"
+)
+
+var resizeSignals = []os.Signal{syscall.SIGWINCH}
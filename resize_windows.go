@@ -0,0 +1,8 @@
+//go:build windows
+
+package prompt
+
+import "os"
+
+// Windows doesn't signal when the console is resized.
+var resizeSignals []os.Signal
@@ -1,72 +1,126 @@
 package prompt
 
 import (
+	"context"
 	"fmt"
-	"github.com/rivo/uniseg"
-	"strings"
 )
 
-const defaultNumLinesShown = 7
-
-type SelectionOption struct {
-	ID          string
-	Name        string
-	Description string
-}
-
-type line struct {
-	optionIndex int
-	text        string
-	isFirst     bool
-}
-
 type Select struct {
 	base
 
+	// A stable identifier for the prompt. When it is set, the AnswerProvider is asked for a response before the prompt
+	// is shown to the user.
+	ID string
+
+	// Supplies responses to skip the prompt. Defaults to DefaultAnswerProvider.
+	AnswerProvider AnswerProvider
+
 	// The question to display to the user
 	Question string
 
 	// Array of options for the user to select
 	Options []SelectionOption
 
+	// The ID or name of the option that the cursor starts on, which is also the response when an empty response is
+	// read without the interactive prompt. Overrides DefaultIndex when it is set.
+	Default string
+
+	// The index of the option that the cursor starts on when Default isn't set
+	// Default is 0
+	DefaultIndex int
+
 	// The number of lines that will be shown at a time
 	// Default is 7
 	NumLinesShown int
 
+	// How the text typed by the user is matched against the options
+	// Default is FilterContains
+	FilterMode FilterMode
+
+	// Matches the options against the text typed by the user, listing the ones with the highest score first. Overrides
+	// FilterMode when it is set.
+	FilterFunc FilterFunc
+
+	// Whether the text typed by the user is also matched against the description of each option
+	ShouldFilterDescription bool
+
+	// Whether the text typed by the user is also matched against the ID of each option
+	ShouldFilterID bool
+
 	// Called when a key is pressed but before it is processed. Return `false` to cancel the event.
 	OnKeyFunc func(Prompt, Key) bool
 
-	offset int
-	cursor int
-	filter string
+	// The terminal to show the prompt on. Defaults to DefaultTerminal.
+	Terminal Terminal
+
+	// The symbols and colors to draw the prompt with. Defaults to DefaultTheme.
+	Theme *Theme
+
+	list optionList
 
-	lines []line
+	// Whether the cursor has been moved to the default option. It stays where it is when the prompt is shown again
+	// after being paused.
+	isCursorPlaced bool
 }
 
+// Show displays the prompt to the user and blocks the current Go routine until the user submits
 func (s *Select) Show() error {
-	err := s.show()
+	return s.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (s *Select) ShowContext(ctx context.Context) error {
+	err := s.show(ctx, s.Terminal, s.Theme)
 	if err != nil {
 		return err
 	}
+	defer s.restoreAfterPanic()
 
-	s.computeLines()
-	s.offset = s.NumLinesToShow() / 2
+	answer, hasAnswer, err := s.lookupAnswer(s.ID, s.AnswerProvider)
+	if err != nil || hasAnswer {
+		if err == nil {
+			err = s.setResponse(answer)
+		}
 
+		return s.finishWithResponse(err, s.Question, s.render, s.responseText)
+	}
+
+	if s.isLineMode() {
+		return s.showLines(ctx)
+	}
+
+	s.list.numLinesShown = s.NumLinesShown
+	s.list.matcher = newOptionMatcher(s.FilterMode, s.FilterFunc, s.ShouldFilterDescription, s.ShouldFilterID)
+	s.list.setOptions(s.Options)
+	s.list.fitWidth(s.output.outputWidth, s.theme, 0)
+	if !s.isCursorPlaced {
+		s.list.moveToOption(s.defaultOptionIndex())
+		s.isCursorPlaced = true
+	}
+
+	s.resizeFunc = s.resize
+	s.isInputEmptyFunc = func() bool { return s.list.filter == "" }
 	s.output.hideCursor()
 	s.render(false)
 
 	for s.State() == Showing {
-		nextKey, err := s.nextKey()
+		nextKey, err := s.nextKey(ctx)
 		if err != nil {
 			s.output.showCursor()
-			s.finish()
-			return err
+			return s.abort(ctx, err)
 		}
 
 		s.handleInput(nextKey)
 	}
 
-	return nil
+	return s.closeErr
+}
+
+// resize wraps the options to the new width of the terminal and redraws the prompt.
+func (s *Select) resize() {
+	s.list.fitWidth(s.output.outputWidth, s.theme, 0)
+	s.render(false)
 }
 
 func (s *Select) handleInput(input Key) {
@@ -75,84 +129,24 @@ func (s *Select) handleInput(input Key) {
 	}
 
 	if input == ControlUp {
-		if len(s.filteredOptions()) > 1 {
-			for {
-				s.cursor--
-				if s.cursor < 0 {
-					s.cursor = len(s.lines) - 1
-				}
-
-				curLine := s.lines[s.cursor]
-
-				// If we haven't reached the first line then keep going.
-				if !curLine.isFirst {
-					continue
-				}
-
-				// If we're on a filtered out line then keep going
-				if !s.matchesFilter(s.Options[curLine.optionIndex]) {
-					continue
-				}
-
-				// Otherwise, we've completed our search.
-				break
-			}
-		}
+		s.list.up()
 	} else if input == ControlDown {
-		if len(s.filteredOptions()) > 1 {
-			for {
-				s.cursor++
-				if s.cursor == len(s.lines) {
-					s.cursor = 0
-				}
-
-				curLine := s.lines[s.cursor]
-
-				// If we're on a filtered out line then keep going
-				if !s.matchesFilter(s.Options[curLine.optionIndex]) {
-					continue
-				}
-
-				// Otherwise, we've completed our search.
-				break
-			}
-		}
+		s.list.down()
+	} else if input == ControlPageUp {
+		s.list.pageUp()
+	} else if input == ControlPageDown {
+		s.list.pageDown()
 	} else if input == ControlEnter {
-		if len(s.filteredOptions()) != 0 {
+		if len(s.list.matches) != 0 {
 			s.output.showCursor()
 			s.render(true)
 			s.finish()
 			return
 		}
 	} else if input.IsText() {
-		s.filter += string(input.Rune())
-
-		if len(s.filteredOptions()) > 0 && !s.matchesFilter(s.curOption()) {
-			closestValidLine := -1
-			for i, line := range s.lines {
-				// We're only interested in the first lines.
-				if !line.isFirst {
-					continue
-				}
-
-				// This line doesn't match the filter
-				if !s.matchesFilter(s.Options[line.optionIndex]) {
-					continue
-				}
-
-				if closestValidLine == -1 {
-					closestValidLine = i
-				} else if abs(s.cursor-i) < abs(s.cursor-closestValidLine) {
-					closestValidLine = i
-				}
-			}
-
-			s.cursor = closestValidLine
-		}
+		s.list.addToFilter(input.Rune())
 	} else if input == ControlBackspace {
-		if s.filter != "" {
-			s.filter = s.filter[:len(s.filter)-1]
-		}
+		s.list.removeFromFilter()
 	}
 
 	if s.State() != Waiting {
@@ -160,181 +154,83 @@ func (s *Select) handleInput(input Key) {
 	}
 }
 
-func (s *Select) curOption() SelectionOption {
-	return s.Options[s.lines[s.cursor].optionIndex]
-}
-
-func (s *Select) matchesFilter(option SelectionOption) bool {
-	if s.filter == "" {
-		return true
-	}
-
-	if strings.Contains(strings.ToLower(option.Name), strings.ToLower(s.filter)) {
-		return true
-	}
-
-	return false
-}
-
-func (s *Select) filteredOptions() []SelectionOption {
-	var result []SelectionOption
-	for _, option := range s.Options {
-		if s.matchesFilter(option) {
-			result = append(result, option)
-		}
-	}
-
-	return result
-}
-
 func (s *Select) NumLinesToShow() int {
-	if s.NumLinesShown <= 0 {
-		return min(defaultNumLinesShown, len(s.lines))
-	}
-
-	return min(s.NumLinesShown, len(s.lines))
+	return s.list.numLinesToShow()
 }
 
 func (s *Select) render(isFinished bool) {
 	s.output.clear()
 
-	s.output.writeColor("? ", colorGreen)
-	s.output.write(s.Question)
+	s.writeQuestion(s.Question)
 	s.output.write(": ")
 	if isFinished {
-		s.output.writeColor(fmt.Sprintf("%s: %s", s.Response().Name, s.Response().Description), colorCyan)
+		s.writeAnswer(fmt.Sprintf("%s: %s", s.Response().Name, stripMarkup(s.Response().Description)))
 		return
 	} else {
-		s.output.writeColor("(Use arrow keys) (Type to filter)", colorGreen)
+		s.writeHint("(Use arrow keys) (Type to filter)")
 	}
 	s.output.nextLine()
 
-	cursorLine := s.lines[s.cursor]
+	s.list.setHeight(s.output.outputHeight - s.output.cursorRow)
+	s.list.render(s.output, s.theme, nil)
 
-	startOffset := (-s.NumLinesToShow() / 2) + s.offset
-	endOffset := (s.NumLinesToShow() / 2) + s.offset
+	s.output.flush()
+}
 
-	fillRemainingWithBlank := false
-	if len(s.filteredOptions()) == 0 {
-		s.output.writeColorLn(s.filter, colorRed)
-		fillRemainingWithBlank = true
-		startOffset++
+// showLines reads the ID or name of the selected option without the interactive prompt.
+func (s *Select) showLines(ctx context.Context) error {
+	line, err := s.readLine(ctx, s.Question)
+	if err == nil {
+		err = s.setResponse(line)
 	}
 
-	for offset := startOffset; offset <= endOffset; offset++ {
-		lineIndex := s.actualLineNumber(s.cursor + offset)
-
-		// We've looped back to the start
-		if offset != startOffset && lineIndex == s.actualLineNumber(s.cursor+startOffset) {
-			fillRemainingWithBlank = true
-		}
-		if fillRemainingWithBlank {
-			s.output.nextLine()
-			continue
-		}
-
-		line := s.lines[lineIndex]
-		option := s.Options[line.optionIndex]
-
-		if !s.matchesFilter(option) {
-			endOffset++
-			continue
-		}
+	return s.finishWithResponse(err, s.Question, s.render, s.responseText)
+}
 
-		if lineIndex == s.cursor {
-			s.output.writeColor("> ", colorCyan)
-		} else {
-			s.output.write("  ")
-		}
+func (s *Select) responseText() string {
+	return s.Response().Name
+}
 
-		if line.isFirst {
-			option := s.Options[line.optionIndex]
-
-			redRemaining := 0
-			for i, c := range line.text {
-				if i >= len(option.Name) {
-					if line.optionIndex == cursorLine.optionIndex {
-						s.output.writeColor(string(c), colorCyan)
-					} else {
-						s.output.write(string(c))
-					}
-				} else {
-					if s.filter != "" && strings.HasPrefix(strings.ToLower(option.Name[i:]), strings.ToLower(s.filter)) {
-						s.output.writeColor(string(c), colorRed)
-						redRemaining = len(s.filter) - 1
-					} else if redRemaining > 0 {
-						s.output.writeColor(string(c), colorRed)
-						redRemaining--
-					} else if line.optionIndex == cursorLine.optionIndex {
-						s.output.writeColor(string(c), colorCyan)
-					} else {
-						s.output.write(string(c))
-					}
-				}
-			}
-
-			s.output.nextLine()
-		} else {
-			if line.optionIndex == cursorLine.optionIndex {
-				s.output.writeColorLn(line.text, colorCyan)
-			} else {
-				s.output.writeLn(line.text)
-			}
-		}
+// defaultOptionIndex returns the index of the option given by Default or DefaultIndex. It falls back to the first
+// option when they don't match an option.
+func (s *Select) defaultOptionIndex() int {
+	if s.Default != "" {
+		return max(0, findOption(s.Options, s.Default))
 	}
 
-	if len(s.lines) > s.NumLinesToShow() {
-		s.output.writeColor("(Move up and down to reveal more choices)", colorGreen)
+	if s.DefaultIndex < 0 || s.DefaultIndex >= len(s.Options) {
+		return 0
 	}
 
-	s.output.flush()
+	return s.DefaultIndex
 }
 
-func (s *Select) computeLines() {
-	s.lines = make([]line, 0, len(s.Options))
-
-	longestName := s.longestName()
-
-	for optionIndex, option := range s.Options {
-		wrappedDescription := wrapString(option.Description, s.output.outputWidth-longestName-4)
-
-		for i, wrapped := range wrappedDescription {
-			var currentLineText string
-			if i == 0 {
-				padding := strings.Repeat(" ", longestName-uniseg.GraphemeClusterCount(option.Name))
-				currentLineText = fmt.Sprintf("%s: %s%s", option.Name, padding, wrapped)
-			} else {
-				currentLineText = fmt.Sprintf("%s  %s", strings.Repeat(" ", longestName), wrapped)
-			}
+// setResponse moves the cursor to the option with the ID or name given by the response. An empty response selects the
+// default option.
+func (s *Select) setResponse(response string) error {
+	optionIndex := findOption(s.Options, response)
+	if response == "" && len(s.Options) != 0 {
+		optionIndex = s.defaultOptionIndex()
+	}
 
-			s.lines = append(s.lines, line{
-				optionIndex: optionIndex,
-				text:        currentLineText,
-				isFirst:     i == 0,
-			})
-		}
+	if optionIndex == -1 {
+		return &ValidationError{Message: fmt.Sprintf("%q doesn't match any option", response)}
 	}
-}
 
-func (s *Select) actualLineNumber(line int) int {
-	if line < 0 {
-		return line + len(s.lines)
-	} else if line >= len(s.lines) {
-		return line - len(s.lines)
+	s.list.numLinesShown = s.NumLinesShown
+	s.list.filter = ""
+	s.list.setOptions(s.Options)
+	if s.output != nil {
+		s.list.fitWidth(s.output.outputWidth, s.theme, 0)
+	} else {
+		s.list.fitWidth(defaultOutputWidth, s.theme, 0)
 	}
+	s.list.moveToOption(optionIndex)
+	s.isCursorPlaced = true
 
-	return line
+	return nil
 }
 
 func (s *Select) Response() SelectionOption {
-	return s.curOption()
-}
-
-func (s *Select) longestName() int {
-	longestName := 0
-	for _, option := range s.Options {
-		longestName = max(uniseg.GraphemeClusterCount(option.Name), longestName)
-	}
-
-	return longestName
+	return s.list.curOption()
 }
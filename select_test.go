@@ -0,0 +1,149 @@
+package prompt_test
+
+import (
+	"fmt"
+	"github.com/JosephNaberhaus/prompt"
+	"github.com/JosephNaberhaus/prompt/prompttest"
+	"io"
+	"strings"
+	"testing"
+)
+
+const (
+	keyDown      = "\x1b[B"
+	keyEnter     = "\r"
+	keyBackspace = "\x7f"
+)
+
+var benchmarkOptionCounts = []int{1_000, 10_000, 100_000}
+
+// Moving the cursor should cost the same no matter how many options there are.
+func BenchmarkSelectMove(b *testing.B) {
+	for _, numOptions := range benchmarkOptionCounts {
+		b.Run(fmt.Sprintf("options=%d", numOptions), func(b *testing.B) {
+			numBytes := runSelect(b, makeOptions(numOptions), prompt.FilterContains, keyDown)
+			b.ReportMetric(float64(numBytes)/float64(b.N), "B/key")
+		})
+	}
+}
+
+// Typing into the filter should only grow with the number of options that still match.
+func BenchmarkSelectFilter(b *testing.B) {
+	// Narrow the filter down to a few options and then remove it again.
+	query := "src/pkg7/file1"
+	keys := query + strings.Repeat(keyBackspace, len(query))
+	keysPerOp := 2 * len(query)
+
+	modes := []struct {
+		name string
+		mode prompt.FilterMode
+	}{
+		{"contains", prompt.FilterContains},
+		{"fuzzy", prompt.FilterFuzzy},
+	}
+
+	for _, mode := range modes {
+		for _, numOptions := range benchmarkOptionCounts {
+			b.Run(fmt.Sprintf("%s/options=%d", mode.name, numOptions), func(b *testing.B) {
+				numBytes := runSelect(b, makeOptions(numOptions), mode.mode, keys)
+				b.ReportMetric(float64(b.Elapsed().Nanoseconds())/float64(b.N*keysPerOp), "ns/key")
+				b.ReportMetric(float64(numBytes)/float64(b.N*keysPerOp), "B/key")
+			})
+		}
+	}
+}
+
+// runSelect shows a Select that reads the keys b.N times and then submits. It returns the number of bytes written.
+func runSelect(b *testing.B, options []prompt.SelectionOption, filterMode prompt.FilterMode, keys string) int {
+	input := &keyReader{keys: keys, remaining: b.N}
+	output := &byteCounter{}
+
+	p := prompt.Select{
+		Question:   "Select a file",
+		Options:    options,
+		FilterMode: filterMode,
+		Terminal:   prompt.NewTerminal(input, output, prompt.FixedSize(120, 40)),
+	}
+
+	b.ResetTimer()
+
+	err := p.Show()
+	if err != nil {
+		b.Fatal(err)
+	}
+
+	b.StopTimer()
+
+	return output.numBytes
+}
+
+// keyReader returns the keys once per read until none remain and then Enter, like a terminal delivering key presses.
+// Escape sequences are never split across reads.
+type keyReader struct {
+	keys      string
+	remaining int
+}
+
+func (k *keyReader) Read(p []byte) (int, error) {
+	if k.remaining < 0 {
+		return 0, io.EOF
+	}
+
+	k.remaining--
+	if k.remaining < 0 {
+		return copy(p, keyEnter), nil
+	}
+
+	return copy(p, k.keys), nil
+}
+
+// byteCounter counts the bytes written to it and then discards them.
+type byteCounter struct {
+	numBytes int
+}
+
+func (c *byteCounter) Write(p []byte) (int, error) {
+	c.numBytes += len(p)
+	return len(p), nil
+}
+
+func makeOptions(numOptions int) []prompt.SelectionOption {
+	options := make([]prompt.SelectionOption, numOptions)
+	for i := range options {
+		options[i] = prompt.SelectionOption{
+			Name:        fmt.Sprintf("src/pkg%d/file%d.go", i%100, i),
+			Description: fmt.Sprintf("%d lines", i%1000),
+		}
+	}
+
+	return options
+}
+
+func TestSelectRender(t *testing.T) {
+	terminal := prompttest.NewTerminal(40, 10, prompt.ControlDown)
+	terminal.Press(prompttest.Type("an")...)
+	terminal.Press(prompt.ControlBackspace, prompt.ControlBackspace, prompt.ControlUp, prompt.ControlEnter)
+
+	p := prompt.Select{
+		Question: "Pick a fruit",
+		Options: []prompt.SelectionOption{
+			{Name: "Apple", Description: "Crisp and sweet"},
+			{Name: "Banana", Description: "Soft and yellow"},
+			{Name: "Cherry", Description: "Small and red"},
+			{Name: "Mango", Description: "Tropical"},
+		},
+		NumLinesShown: 3,
+		Terminal:      terminal,
+	}
+
+	err := p.Show()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Response().Name != "Apple" {
+		t.Errorf("expected Apple to be selected but got %s", p.Response().Name)
+	}
+
+	prompttest.AssertGoldenFrames(t, "select_render", terminal)
+}
@@ -0,0 +1,69 @@
+package prompt
+
+import (
+	"context"
+	"fmt"
+)
+
+// SelectOf is a Select over values of any type. The options are made from the values, and the response is the value
+// that the user selected, so callers don't have to look it up from the ID of an option.
+type SelectOf[T any] struct {
+	Select
+
+	// The values for the user to select from. They replace the Options of the Select when the prompt is shown.
+	Values []T
+
+	// Returns the name that is shown for a value. Defaults to formatting the value with fmt.Sprint.
+	NameFunc func(T) string
+
+	// Returns the description that is shown for a value. Default is no description.
+	DescriptionFunc func(T) string
+
+	// Returns the stable identifier of a value, which answer providers and line mode can respond with instead of its
+	// name. Default is no identifier.
+	IDFunc func(T) string
+}
+
+// Show displays the prompt to the user and blocks the current Go routine until the user submits
+func (s *SelectOf[T]) Show() error {
+	return s.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (s *SelectOf[T]) ShowContext(ctx context.Context) error {
+	s.Options = make([]SelectionOption, len(s.Values))
+	for i, value := range s.Values {
+		s.Options[i] = s.option(value)
+	}
+
+	return s.Select.ShowContext(ctx)
+}
+
+func (s *SelectOf[T]) option(value T) SelectionOption {
+	option := SelectionOption{Name: fmt.Sprint(value)}
+	if s.NameFunc != nil {
+		option.Name = s.NameFunc(value)
+	}
+
+	if s.DescriptionFunc != nil {
+		option.Description = s.DescriptionFunc(value)
+	}
+
+	if s.IDFunc != nil {
+		option.ID = s.IDFunc(value)
+	}
+
+	return option
+}
+
+// Response returns the value that the user selected, or the zero value if no value has been selected.
+func (s *SelectOf[T]) Response() T {
+	optionIndex := s.list.curOptionIndex()
+	if optionIndex == -1 || optionIndex >= len(s.Values) {
+		var zero T
+		return zero
+	}
+
+	return s.Values[optionIndex]
+}
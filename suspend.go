@@ -0,0 +1,44 @@
+package prompt
+
+// Suspender is implemented by terminals that can suspend the process with job control.
+type Suspender interface {
+	// Suspend stops the process until it is continued, such as by the shell's fg command. It's called while the
+	// terminal is closed.
+	Suspend() error
+}
+
+// ShouldSuspendOnCtrlZ is whether pressing Ctrl-Z suspends the process while a prompt is shown, like it would outside
+// of a prompt. The prompt is removed while the process is suspended and drawn again as it was when the process is
+// continued. It only has an effect on terminals that implement Suspender. When it is false, Ctrl-Z is passed to the
+// prompt as ControlCtrlZ.
+var ShouldSuspendOnCtrlZ = false
+
+// suspend gives the terminal back and suspends the process, then takes the terminal again and redraws the prompt once
+// the process is continued.
+func (b *base) suspend(suspender Suspender) error {
+	b.output.suspend()
+	err := b.closeTerminal()
+	if err != nil {
+		return err
+	}
+
+	suspendErr := suspender.Suspend()
+
+	err = b.terminal.Open()
+	if err != nil {
+		return &TerminalError{Op: "open", Err: err}
+	}
+
+	width, height, err := b.terminal.Size()
+	if err != nil {
+		return &TerminalError{Op: "get the size of", Err: err}
+	}
+
+	// The terminal might have been resized while the process was suspended.
+	b.output.resume(width, height)
+	if b.resizeFunc != nil {
+		b.resizeFunc()
+	}
+
+	return suspendErr
+}
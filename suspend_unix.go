@@ -0,0 +1,36 @@
+//go:build !windows
+
+package prompt
+
+import (
+	"fmt"
+	"os"
+	"os/signal"
+	"syscall"
+	"time"
+)
+
+// How long to wait for the process to be continued before deciding that it wasn't stopped
+const orphanedSuspendTimeout = 500 * time.Millisecond
+
+// Suspend stops the process group in the same way as pressing Ctrl-Z does outside of raw mode, and waits for it to be
+// continued.
+func (s *stdTerminal) Suspend() error {
+	continued := make(chan os.Signal, 1)
+	signal.Notify(continued, syscall.SIGCONT)
+	defer signal.Stop(continued)
+
+	err := syscall.Kill(0, syscall.SIGTSTP)
+	if err != nil {
+		return fmt.Errorf("couldn't suspend the process: %w", err)
+	}
+
+	// The process stops as soon as the signal is sent, so the signal to continue is already waiting once it does. An
+	// orphaned process group ignores the signal to stop instead, in which case there's nothing to wait for.
+	select {
+	case <-continued:
+	case <-time.After(orphanedSuspendTimeout):
+	}
+
+	return nil
+}
@@ -0,0 +1,229 @@
+package prompt
+
+import (
+	"bufio"
+	"context"
+	"errors"
+	"fmt"
+	escapes "github.com/snugfox/ansi-escapes"
+	"golang.org/x/term"
+	"io"
+	"os"
+	"sync"
+)
+
+// Terminal is the device that prompts are displayed on and read key presses from.
+type Terminal interface {
+	io.Writer
+
+	// Open is called before a prompt is shown. It should prepare the terminal for reading individual key presses.
+	Open() error
+
+	// Close is called once a prompt stops being shown and should undo anything done by Open.
+	Close() error
+
+	// ReadKey blocks until the next key is pressed and returns it. It must return ctx.Err() if the context is done
+	// first. A key that arrives after that should be returned by the next call rather than dropped.
+	ReadKey(ctx context.Context) (Key, error)
+
+	// Size returns the number of columns and rows of the terminal.
+	Size() (width, height int, err error)
+}
+
+// DefaultTerminal is used by every prompt that doesn't specify its own Terminal. It reads keys from the controlling
+// terminal and writes to stdout. When stdin isn't a terminal, responses are read from stdin one line at a time instead.
+var DefaultTerminal Terminal = NewStdTerminal(os.Stdout)
+
+// SizeFunc returns the number of columns and rows of a terminal.
+type SizeFunc func() (width, height int, err error)
+
+// FixedSize returns a SizeFunc that always reports the given dimensions.
+func FixedSize(width, height int) SizeFunc {
+	return func() (int, int, error) {
+		return width, height, nil
+	}
+}
+
+// FileSize returns a SizeFunc that queries the dimensions of the terminal the file is attached to.
+func FileSize(file *os.File) SizeFunc {
+	return func() (int, int, error) {
+		dimensions, err := escapes.GetConsoleSize(file.Fd())
+		if err != nil {
+			return 0, 0, fmt.Errorf("couldn't get console size: %w", err)
+		}
+
+		return dimensions.Cols, dimensions.Rows, nil
+	}
+}
+
+type stdTerminal struct {
+	lineReader
+
+	out          *os.File
+	resizes      *resizeWatcher
+	terminations terminationWatcher
+
+	// Guards the terminal below, which is also restored when the process is asked to terminate
+	mu sync.Mutex
+
+	// The controlling terminal while it's open, along with its state from before it was put into raw mode
+	tty       *os.File
+	ttyState  *term.State
+	ttyReader *ttyReader
+	decoder   *keyDecoder
+}
+
+// NewStdTerminal creates a Terminal that reads keys from the controlling terminal of the process and writes to the
+// given file. Use os.Stderr to keep prompts out of stdout when it is being piped to another program. If stdin isn't a
+// terminal then prompts fall back to reading lines from it.
+func NewStdTerminal(out *os.File) Terminal {
+	return &stdTerminal{
+		lineReader: lineReader{reader: bufio.NewReader(os.Stdin)},
+		out:        out,
+		resizes:    newResizeWatcher(),
+	}
+}
+
+func (s *stdTerminal) IsInteractive() bool {
+	return term.IsTerminal(int(os.Stdin.Fd()))
+}
+
+func (s *stdTerminal) Write(p []byte) (int, error) {
+	return s.out.Write(p)
+}
+
+// Open puts the controlling terminal into raw mode and starts decoding the keys read from it. The terminal is opened
+// separately from stdin, and is read in a way that is stopped when it is closed, so that no input is read while a
+// prompt isn't being shown.
+func (s *stdTerminal) Open() error {
+	tty, err := os.OpenFile(ttyPath, os.O_RDWR, 0)
+	if err != nil {
+		return fmt.Errorf("can't open the controlling terminal: %w", err)
+	}
+
+	reader, err := newTTYReader(tty)
+	if err != nil {
+		tty.Close()
+		return fmt.Errorf("can't read from the controlling terminal: %w", err)
+	}
+
+	state, err := makeRaw(tty)
+	if err != nil {
+		reader.Close()
+		tty.Close()
+		return fmt.Errorf("can't put the terminal into raw mode: %w", err)
+	}
+
+	s.mu.Lock()
+	s.tty, s.ttyState, s.ttyReader = tty, state, reader
+	s.decoder = newKeyDecoder(reader)
+	s.mu.Unlock()
+
+	s.resizes.start()
+	s.terminations.start(func() {
+		s.restore()
+		s.out.WriteString(escapes.CursorShow + "\n")
+	})
+	return nil
+}
+
+func (s *stdTerminal) Close() error {
+	s.resizes.halt()
+	s.terminations.halt()
+	return s.restore()
+}
+
+// restore takes the terminal out of raw mode and stops reading from it.
+func (s *stdTerminal) restore() error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.tty == nil {
+		return nil
+	}
+
+	s.decoder.close()
+	readerErr := s.ttyReader.Close()
+	err := restoreState(s.tty, s.ttyState)
+	closeErr := s.tty.Close()
+	s.tty, s.ttyState, s.ttyReader, s.decoder = nil, nil, nil, nil
+
+	return errors.Join(readerErr, err, closeErr)
+}
+
+func (s *stdTerminal) Resized() <-chan struct{} {
+	return s.resizes.resized
+}
+
+func (s *stdTerminal) ReadKey(ctx context.Context) (Key, error) {
+	s.mu.Lock()
+	decoder := s.decoder
+	s.mu.Unlock()
+
+	if decoder == nil {
+		return nil, errors.New("the terminal isn't open")
+	}
+
+	return decoder.readKey(ctx)
+}
+
+func (s *stdTerminal) Size() (int, int, error) {
+	return FileSize(s.out)()
+}
+
+// StreamTerminal is a Terminal that decodes key presses from a reader.
+type StreamTerminal struct {
+	in      io.Reader
+	out     io.Writer
+	size    SizeFunc
+	resizes *resizeWatcher
+
+	// Decodes the keys read from in. It's started by the first call to ReadKey and keeps reading in the background from
+	// then on, so that a key that arrives after a context is done is returned by the next call.
+	decoder *keyDecoder
+}
+
+// NewTerminal creates a Terminal that decodes key presses from in and writes to out. The caller is responsible for
+// putting the underlying device into raw mode, which is usually already the case for a pty or an SSH channel.
+func NewTerminal(in io.Reader, out io.Writer, size SizeFunc) *StreamTerminal {
+	return &StreamTerminal{
+		in:      in,
+		out:     out,
+		size:    size,
+		resizes: newResizeWatcher(),
+	}
+}
+
+// NotifyResized tells the prompt being shown that the value returned by the SizeFunc has changed, such as when an SSH
+// client sends a window change request.
+func (s *StreamTerminal) NotifyResized() {
+	s.resizes.notify()
+}
+
+func (s *StreamTerminal) Resized() <-chan struct{} {
+	return s.resizes.resized
+}
+
+func (s *StreamTerminal) Write(p []byte) (int, error) {
+	return s.out.Write(p)
+}
+
+func (s *StreamTerminal) Open() error {
+	return nil
+}
+
+func (s *StreamTerminal) Close() error {
+	return nil
+}
+
+func (s *StreamTerminal) ReadKey(ctx context.Context) (Key, error) {
+	if s.decoder == nil {
+		s.decoder = newKeyDecoder(s.in)
+	}
+
+	return s.decoder.readKey(ctx)
+}
+
+func (s *StreamTerminal) Size() (int, int, error) {
+	return s.size()
+}
@@ -0,0 +1,66 @@
+package prompt
+
+import (
+	"os"
+	"os/signal"
+	"syscall"
+)
+
+// The signals that ask the process to terminate, which would otherwise leave the terminal in raw mode
+var terminationSignals = []os.Signal{syscall.SIGTERM, syscall.SIGHUP}
+
+// ShouldTerminateOnSignal is whether the default terminal sends SIGTERM or SIGHUP to the process again after restoring
+// the terminal, so that the process terminates as it would have without a prompt being shown. Set it to false when the
+// application handles these signals itself with signal.Notify. The prompt that is being shown then returns an error
+// and the application is left to shut down, instead of receiving the signal a second time.
+// Default is true
+var ShouldTerminateOnSignal = true
+
+// terminationWatcher restores the terminal when the process is asked to terminate while it is running. Only its own
+// channel stops receiving the signals afterwards, so handlers registered by the application keep working.
+type terminationWatcher struct {
+	signals chan os.Signal
+	stop    chan struct{}
+}
+
+func (t *terminationWatcher) start(restore func()) {
+	t.signals = make(chan os.Signal, 1)
+	t.stop = make(chan struct{})
+	signal.Notify(t.signals, terminationSignals...)
+
+	go func(signals chan os.Signal, stop <-chan struct{}) {
+		select {
+		case sig := <-signals:
+			restore()
+			signal.Stop(signals)
+			if ShouldTerminateOnSignal {
+				terminate(sig)
+			}
+		case <-stop:
+		}
+	}(t.signals, t.stop)
+}
+
+func (t *terminationWatcher) halt() {
+	if t.signals == nil {
+		return
+	}
+
+	signal.Stop(t.signals)
+	close(t.stop)
+	t.signals = nil
+}
+
+// terminate sends the signal to the process again. When nothing else is notified of it, the signal terminates the
+// process the same way it would have if it had never been caught. If it can't be sent then the process exits with the
+// status that a shell reports for a process killed by the signal.
+func terminate(sig os.Signal) {
+	process, err := os.FindProcess(os.Getpid())
+	if err == nil {
+		err = process.Signal(sig)
+	}
+
+	if err != nil {
+		os.Exit(128 + int(sig.(syscall.Signal)))
+	}
+}
@@ -1,14 +1,24 @@
 package prompt
 
 import (
+	"context"
 	"fmt"
 	editor "github.com/JosephNaberhaus/texteditor"
+	"math"
+	"strings"
 	"unicode"
 )
 
 type Text struct {
 	base
 
+	// A stable identifier for the prompt. When it is set, the AnswerProvider is asked for a response before the prompt
+	// is shown to the user.
+	ID string
+
+	// Supplies responses to skip the prompt. Defaults to DefaultAnswerProvider.
+	AnswerProvider AnswerProvider
+
 	// The question to display to the user
 	Question string
 
@@ -22,6 +32,12 @@ type Text struct {
 	// Called when a key is pressed but before it is processed. Return `false` to cancel the event.
 	OnKeyFunc func(Prompt, Key) bool
 
+	// The terminal to show the prompt on. Defaults to DefaultTerminal.
+	Terminal Terminal
+
+	// The symbols and colors to draw the prompt with. Defaults to DefaultTheme.
+	Theme *Theme
+
 	// Whether to show the character count to the user
 	ShouldShowCharacterCount bool
 
@@ -38,29 +54,56 @@ type Text struct {
 
 // Show displays the prompt to the user and blocks the current Go routine until the user submits
 func (t *Text) Show() error {
-	err := t.show()
+	return t.ShowContext(context.Background())
+}
+
+// ShowContext is like Show but stops showing the prompt and returns the context's error if it is done before the user
+// submits
+func (t *Text) ShowContext(ctx context.Context) error {
+	err := t.show(ctx, t.Terminal, t.Theme)
 	if err != nil {
 		return err
 	}
+	defer t.restoreAfterPanic()
+
+	answer, hasAnswer, err := t.lookupAnswer(t.ID, t.AnswerProvider)
+	if err != nil || hasAnswer {
+		if err == nil {
+			err = t.setResponse(answer)
+		}
+
+		return t.finishWithResponse(err, t.Question, t.render, t.Response)
+	}
+
+	if t.isLineMode() {
+		return t.showLines(ctx)
+	}
 
 	if t.editor == nil {
 		t.editor = editor.NewEditor()
 		t.editor.SetWidth(t.output.outputWidth)
 	}
 
+	t.resizeFunc = t.resize
+	t.isInputEmptyFunc = func() bool { return t.editor.String() == "" }
 	t.render(false)
 
 	for t.State() == Showing {
-		nextKey, err := t.nextKey()
+		nextKey, err := t.nextKey(ctx)
 		if err != nil {
-			t.finish()
-			return err
+			return t.abort(ctx, err)
 		}
 
 		t.handleInput(nextKey)
 	}
 
-	return nil
+	return t.closeErr
+}
+
+// resize wraps the input to the new width of the terminal and redraws the prompt.
+func (t *Text) resize() {
+	t.editor.SetWidth(t.output.outputWidth)
+	t.render(false)
 }
 
 func (t *Text) handleInput(input Key) {
@@ -123,57 +166,100 @@ func (t *Text) handleInput(input Key) {
 func (t *Text) render(isFinished bool) {
 	t.output.clear()
 
-	t.output.writeColor("? ", colorGreen)
-	t.output.write(t.Question)
+	t.writeQuestion(t.Question)
 
 	validatorMessage := t.validate()
 	isValid := validatorMessage == ""
 
 	if !t.IsSingleLine {
+		t.output.write(": ")
 		if t.editor.Empty() && isValid {
-			t.output.write(": (press enter to skip)")
+			t.writeHint("(press enter to skip)")
 		} else {
-			t.output.write(": (enter two empty lines to submit)")
+			t.writeHint("(enter two empty lines to submit)")
 		}
 	}
 
 	t.output.writeLn(":")
 
-	editorStartingRow := t.output.cursorRow
-
 	if t.ShouldShowCharacterCount {
 		prefix := fmt.Sprintf("(%d) ", t.editor.NumGraphemes())
 		t.editor.SetFirstLineIndent(len(prefix))
 
 		if isValid {
-			t.output.writeColor(prefix, colorCyan)
+			t.output.writeStyle(prefix, t.theme.AnswerStyle)
 		} else {
-			t.output.writeColor(prefix, colorRed)
+			t.output.writeStyle(prefix, t.theme.ErrorStyle)
 		}
 	}
 
 	if isFinished {
-		t.output.writeColor(t.editor.String(), colorCyan)
-	} else {
+		t.output.writeStyle(t.editor.String(), t.theme.AnswerStyle)
+		return
+	}
 
-		if isValid {
-			t.output.writeColor(t.editor.String(), colorWhite)
-		} else {
-			t.output.writeColor(t.editor.String(), colorRed)
+	inputStyle := Style{}
+	if !isValid {
+		inputStyle = t.theme.ErrorStyle
+	}
 
-			if t.didAttemptSubmit {
-				t.output.nextLine()
-				t.output.writeColor(">> ", colorRed)
-				t.output.write(validatorMessage)
-			}
-		}
+	// Only the lines of the response scroll when they don't fit on the terminal.
+	t.output.startScrolling()
+	cursorRow, cursorColumn := t.output.writeEditor(t.editor, inputStyle)
+	t.output.stopScrolling()
 
+	if !isValid && t.didAttemptSubmit {
+		t.output.nextLine()
+		t.writeError(validatorMessage)
 	}
 
-	t.output.setCursor(t.editor.CursorRow()+editorStartingRow, t.editor.CursorColumn())
+	t.output.setCursor(cursorRow, cursorColumn)
 	t.output.flush()
 }
 
+// showLines reads the response without the interactive prompt. Multiline prompts read until the end of the input.
+func (t *Text) showLines(ctx context.Context) error {
+	var paragraphs []string
+	var err error
+	if t.IsSingleLine {
+		var line string
+		line, err = t.readLine(ctx, t.Question)
+		paragraphs = []string{line}
+	} else {
+		paragraphs, err = t.readAllLines(ctx, t.Question)
+	}
+
+	if err == nil {
+		err = t.setResponse(strings.Join(paragraphs, "\n"))
+	}
+
+	return t.finishWithResponse(err, t.Question, t.render, t.Response)
+}
+
+// setResponse replaces the input with the response and validates it.
+func (t *Text) setResponse(response string) error {
+	if t.ShouldForceLowercase {
+		response = strings.ToLower(response)
+	}
+
+	t.editor = editor.NewEditor()
+	if t.OnSubmitMaxLineLength > 0 {
+		t.editor.SetWidth(t.OnSubmitMaxLineLength)
+	} else if t.output != nil {
+		t.editor.SetWidth(t.output.outputWidth)
+	} else {
+		t.editor.SetWidth(math.MaxInt32)
+	}
+	t.editor.Write(response)
+
+	message := t.validate()
+	if message != "" {
+		return &ValidationError{Message: stripMarkup(message)}
+	}
+
+	return nil
+}
+
 func (t *Text) validate() string {
 	if t.ValidatorFunc != nil {
 		return t.ValidatorFunc(t.editor.Paragraphs())
@@ -0,0 +1,142 @@
+package prompt_test
+
+import (
+	"github.com/JosephNaberhaus/prompt"
+	"github.com/JosephNaberhaus/prompt/prompttest"
+	"strings"
+	"testing"
+)
+
+func TestTextWrapsInputByDisplayWidth(t *testing.T) {
+	tests := []struct {
+		name      string
+		width     int
+		input     string
+		expected  string
+		cursorRow int
+		cursorCol int
+	}{
+		{
+			name:      "wide characters fill the row",
+			width:     20,
+			input:     "日本語のテキストです。",
+			expected:  "? Name:\n日本語のテキストです\n。",
+			cursorRow: 2,
+			cursorCol: 2,
+		},
+		{
+			name:      "wide character doesn't fit in the last column",
+			width:     21,
+			input:     "日本語のテキストです。",
+			expected:  "? Name:\n日本語のテキストです\n。",
+			cursorRow: 2,
+			cursorCol: 2,
+		},
+		{
+			name:      "zero width joiner sequences",
+			width:     10,
+			input:     "👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻",
+			expected:  "? Name:\n👩‍💻👩‍💻👩‍💻👩‍💻👩‍💻\n👩‍💻",
+			cursorRow: 2,
+			cursorCol: 2,
+		},
+		{
+			name:      "combining marks",
+			width:     10,
+			input:     strings.Repeat("e\u0301", 11),
+			expected:  "? Name:\n" + strings.Repeat("e\u0301", 10) + "\ne\u0301",
+			cursorRow: 2,
+			cursorCol: 1,
+		},
+		{
+			name:      "words with wide characters",
+			width:     12,
+			input:     "naïve café 東京タワー",
+			expected:  "? Name:\nnaïve café\n東京タワー",
+			cursorRow: 2,
+			cursorCol: 10,
+		},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			terminal := prompttest.NewTerminal(test.width, 10, prompttest.Type(test.input)...)
+			terminal.Press(prompt.Noop, prompt.ControlEnter)
+
+			var shown snapshot
+			p := prompt.Text{
+				Question:     "Name",
+				IsSingleLine: true,
+				Terminal:     terminal,
+				OnKeyFunc:    snapshotOnNoop(terminal, &shown),
+			}
+
+			err := p.Show()
+			if err != nil {
+				t.Fatal(err)
+			}
+
+			assertShown(t, shown, test.expected)
+			if shown.row != test.cursorRow || shown.col != test.cursorCol {
+				t.Errorf("expected the cursor at (%d, %d) but it was at (%d, %d)", test.cursorRow, test.cursorCol, shown.row, shown.col)
+			}
+		})
+	}
+}
+
+func TestTextRender(t *testing.T) {
+	terminal := prompttest.NewTerminal(40, 10, prompttest.Type("hi\n")...)
+	terminal.Press(prompt.ControlBackspace, prompt.ControlBackspace)
+	terminal.Press(prompttest.Type("hello\n")...)
+
+	p := prompt.Text{
+		Question:     "Name",
+		IsSingleLine: true,
+		ValidatorFunc: func(paragraphs []string) string {
+			if len(paragraphs[0]) < 3 {
+				return "Write at least three characters"
+			}
+
+			return ""
+		},
+		Terminal: terminal,
+	}
+
+	err := p.Show()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if p.Response() != "hello" {
+		t.Errorf("expected the response hello but got %s", p.Response())
+	}
+
+	prompttest.AssertGoldenFrames(t, "text_render", terminal)
+}
+
+func TestTextKeepsAnswerTallerThanTerminal(t *testing.T) {
+	terminal := prompttest.NewTerminal(20, 5, prompttest.Type("1\n2\n3\n4\n5\n6\n7\n")...)
+	terminal.Press(prompt.ControlEnter, prompt.ControlEnter)
+
+	p := prompt.Text{
+		Question: "Numbers",
+		Terminal: terminal,
+	}
+
+	err := p.Show()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The rows that were scrolled off the top of the terminal while the answer was committed are still in the
+	// scrollback, starting with the question.
+	actual := strings.Join(append(terminal.Screen.Scrollback(), terminal.Screen.String()), "\n")
+	expected := "" +
+		"? Numbers: (enter\n" +
+		"  two empty lines to\n" +
+		"  submit):\n" +
+		"1\n2\n3\n4\n5\n6\n7"
+	if actual != expected {
+		t.Errorf("unexpected output\n--- expected ---\n%s\n--- actual ---\n%s", expected, actual)
+	}
+}
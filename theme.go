@@ -0,0 +1,107 @@
+package prompt
+
+import "github.com/rivo/uniseg"
+
+// Theme controls the symbols and colors that prompts are drawn with. To customize a theme, start from a copy of one of
+// the presets so that every field is set.
+type Theme struct {
+	// Written before the question
+	QuestionPrefix string
+
+	// Written before the option under the cursor in Select and MultiSelect
+	CursorPrefix string
+
+	// Written before a message explaining why the response isn't valid
+	ErrorPrefix string
+
+	// The style of the question prefix
+	PrefixStyle Style
+
+	// The style of the question
+	QuestionStyle Style
+
+	// The style of hints such as "(Y/n)" and "(Type to filter)"
+	HintStyle Style
+
+	// The style of the response once the prompt is finished and of the option under the cursor
+	AnswerStyle Style
+
+	// The style of the cursor prefix
+	CursorStyle Style
+
+	// The style of validation errors and of input that isn't valid
+	ErrorStyle Style
+
+	// The style of the parts of an option that match the filter
+	MatchStyle Style
+}
+
+// ThemeClassic is the original look of the prompts.
+var ThemeClassic = Theme{
+	QuestionPrefix: "? ",
+	CursorPrefix:   "> ",
+	ErrorPrefix:    ">> ",
+	PrefixStyle:    Style{Foreground: ColorGreen},
+	HintStyle:      Style{Foreground: ColorGreen},
+	AnswerStyle:    Style{Foreground: ColorCyan},
+	CursorStyle:    Style{Foreground: ColorCyan},
+	ErrorStyle:     Style{Foreground: ColorRed},
+	MatchStyle:     Style{Foreground: ColorRed},
+}
+
+// ThemeLight uses colors that are easier to read on a light background.
+var ThemeLight = Theme{
+	QuestionPrefix: "? ",
+	CursorPrefix:   "> ",
+	ErrorPrefix:    ">> ",
+	PrefixStyle:    Style{Foreground: ColorBlue},
+	HintStyle:      Style{Foreground: ColorBlue},
+	AnswerStyle:    Style{Foreground: ColorMagenta},
+	CursorStyle:    Style{Foreground: ColorMagenta},
+	ErrorStyle:     Style{Foreground: ColorRed},
+	MatchStyle:     Style{Foreground: ColorRed, IsUnderlined: true},
+}
+
+// ThemeUnicode uses Unicode symbols in place of the ASCII ones.
+var ThemeUnicode = Theme{
+	QuestionPrefix: "◆ ",
+	CursorPrefix:   "❯ ",
+	ErrorPrefix:    "✗ ",
+	PrefixStyle:    Style{Foreground: RGB(0x5f, 0xd7, 0xff)},
+	QuestionStyle:  Style{IsBold: true},
+	HintStyle:      Style{Foreground: RGB(0x87, 0x87, 0xaf), IsItalic: true},
+	AnswerStyle:    Style{Foreground: RGB(0x5f, 0xd7, 0xff)},
+	CursorStyle:    Style{Foreground: RGB(0x5f, 0xd7, 0xff), IsBold: true},
+	ErrorStyle:     Style{Foreground: RGB(0xff, 0x5f, 0x5f)},
+	MatchStyle:     Style{Foreground: RGB(0xff, 0xd7, 0x00), IsUnderlined: true},
+}
+
+// DefaultTheme is used by every prompt that doesn't specify its own Theme.
+var DefaultTheme = ThemeClassic
+
+// writeQuestion writes the question prefix and the question. A question that is too long for one line is wrapped with
+// the lines after the first lined up under its start.
+func (b *base) writeQuestion(question string) {
+	b.output.writeStyle(b.theme.QuestionPrefix, b.theme.PrefixStyle)
+	b.output.writeWrappedMarkup(question, b.theme.QuestionStyle, b.output.cursorColumn)
+}
+
+// writeHint writes a hint that follows the question, wrapping it in line with the question.
+func (b *base) writeHint(hint string) {
+	b.output.writeWrapped(hint, b.theme.HintStyle, uniseg.StringWidth(b.theme.QuestionPrefix))
+}
+
+// writeAnswer writes the response of a finished prompt, wrapping it in line with the question.
+func (b *base) writeAnswer(answer string) {
+	b.output.writeWrapped(answer, b.theme.AnswerStyle, uniseg.StringWidth(b.theme.QuestionPrefix))
+}
+
+// writeError writes the error prefix and a message explaining why the response isn't valid. Without colors the message
+// is also labelled as an error.
+func (b *base) writeError(message string) {
+	b.output.writeStyle(b.theme.ErrorPrefix, b.theme.ErrorStyle)
+	if b.output.isMonochrome() {
+		b.output.write("error: ")
+	}
+	b.output.writeWrappedMarkup(message, Style{}, b.output.cursorColumn)
+}
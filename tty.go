@@ -0,0 +1,45 @@
+package prompt
+
+import (
+	"os"
+
+	"golang.org/x/term"
+)
+
+// makeRaw puts the terminal into raw mode. The file descriptor is used through SyscallConn rather than Fd, since Fd
+// would take the file out of non-blocking mode.
+func makeRaw(tty *os.File) (*term.State, error) {
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return nil, err
+	}
+
+	var state *term.State
+	var rawErr error
+	err = conn.Control(func(fd uintptr) {
+		state, rawErr = term.MakeRaw(int(fd))
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return state, rawErr
+}
+
+// restoreState takes the terminal out of raw mode.
+func restoreState(tty *os.File, state *term.State) error {
+	conn, err := tty.SyscallConn()
+	if err != nil {
+		return err
+	}
+
+	var restoreErr error
+	err = conn.Control(func(fd uintptr) {
+		restoreErr = term.Restore(int(fd), state)
+	})
+	if err != nil {
+		return err
+	}
+
+	return restoreErr
+}
@@ -0,0 +1,95 @@
+//go:build !windows
+
+package prompt
+
+import (
+	"errors"
+	"os"
+	"sync"
+)
+
+// The controlling terminal of the process
+const ttyPath = "/dev/tty"
+
+// ttyReader reads from the terminal in a way that can be cancelled. Closing the terminal doesn't end a read that is in
+// progress on every platform, such as on macOS where the terminal can't be polled by the Go runtime, so each read
+// waits until there is input or until the reader is closed before reading.
+type ttyReader struct {
+	tty *os.File
+
+	// Closing wakeWriter wakes up a read that is waiting for input
+	wake, wakeWriter *os.File
+
+	// Held while waiting for input, so that closing can wait for the read to stop
+	mu       sync.Mutex
+	isClosed bool
+}
+
+func newTTYReader(tty *os.File) (*ttyReader, error) {
+	wake, wakeWriter, err := os.Pipe()
+	if err != nil {
+		return nil, err
+	}
+
+	return &ttyReader{tty: tty, wake: wake, wakeWriter: wakeWriter}, nil
+}
+
+func (t *ttyReader) Read(p []byte) (int, error) {
+	isReady, err := t.waitForInput()
+	if err != nil {
+		return 0, err
+	}
+
+	if !isReady {
+		return 0, errDecoderClosed
+	}
+
+	return t.tty.Read(p)
+}
+
+// waitForInput returns true once the terminal has input, or false if the reader was closed first.
+func (t *ttyReader) waitForInput() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.isClosed {
+		return false, nil
+	}
+
+	ttyConn, err := t.tty.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	wakeConn, err := t.wake.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var isReady bool
+	var waitErr error
+	err = ttyConn.Control(func(ttyFd uintptr) {
+		controlErr := wakeConn.Control(func(wakeFd uintptr) {
+			isReady, waitErr = waitForInput(int(ttyFd), int(wakeFd))
+		})
+		if controlErr != nil {
+			waitErr = controlErr
+		}
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return isReady, waitErr
+}
+
+// Close stops the read that is in progress, if any. It doesn't close the terminal.
+func (t *ttyReader) Close() error {
+	err := t.wakeWriter.Close()
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.isClosed = true
+	return errors.Join(err, t.wake.Close())
+}
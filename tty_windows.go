@@ -0,0 +1,147 @@
+//go:build windows
+
+package prompt
+
+import (
+	"errors"
+	"os"
+	"sync"
+	"unsafe"
+
+	"golang.org/x/sys/windows"
+)
+
+// The input of the console that the process is attached to
+const ttyPath = "CONIN$"
+
+var (
+	kernel32             = windows.NewLazySystemDLL("kernel32.dll")
+	procPeekConsoleInput = kernel32.NewProc("PeekConsoleInputW")
+	procReadConsoleInput = kernel32.NewProc("ReadConsoleInputW")
+)
+
+const keyEvent = 0x0001
+
+// inputRecord is the INPUT_RECORD structure of the console API.
+type inputRecord struct {
+	eventType uint16
+	_         uint16
+	event     [16]byte
+}
+
+// isCharacter returns whether the record is a key press that reading from the console returns text for. Other records,
+// such as focus changes and modifier keys, are skipped by reading.
+func (r *inputRecord) isCharacter() bool {
+	if r.eventType != keyEvent {
+		return false
+	}
+
+	isKeyDown := *(*int32)(unsafe.Pointer(&r.event[0])) != 0
+	char := *(*uint16)(unsafe.Pointer(&r.event[10]))
+	return isKeyDown && char != 0
+}
+
+// ttyReader reads from the console in a way that can be cancelled, since closing the console doesn't end a read that
+// is in progress. Each read waits until the console has a character to read or until the reader is closed.
+type ttyReader struct {
+	tty *os.File
+
+	// Set to wake up a read that is waiting for input
+	closed windows.Handle
+
+	// Held while waiting for input, so that closing can wait for the read to stop
+	mu       sync.Mutex
+	isClosed bool
+}
+
+func newTTYReader(tty *os.File) (*ttyReader, error) {
+	closed, err := windows.CreateEvent(nil, 1, 0, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	return &ttyReader{tty: tty, closed: closed}, nil
+}
+
+func (t *ttyReader) Read(p []byte) (int, error) {
+	isReady, err := t.waitForInput()
+	if err != nil {
+		return 0, err
+	}
+
+	if !isReady {
+		return 0, errDecoderClosed
+	}
+
+	return t.tty.Read(p)
+}
+
+// waitForInput returns true once the console has a character to read, or false if the reader was closed first.
+func (t *ttyReader) waitForInput() (bool, error) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if t.isClosed {
+		return false, nil
+	}
+
+	conn, err := t.tty.SyscallConn()
+	if err != nil {
+		return false, err
+	}
+
+	var isReady bool
+	var waitErr error
+	err = conn.Control(func(fd uintptr) {
+		isReady, waitErr = t.waitForCharacter(windows.Handle(fd))
+	})
+	if err != nil {
+		return false, err
+	}
+
+	return isReady, waitErr
+}
+
+// waitForCharacter waits for the console to have input, and discards the input that doesn't have a character to read so
+// that reading won't block.
+func (t *ttyReader) waitForCharacter(console windows.Handle) (bool, error) {
+	records := make([]inputRecord, 16)
+	for {
+		event, err := windows.WaitForMultipleObjects([]windows.Handle{console, t.closed}, false, windows.INFINITE)
+		if err != nil {
+			return false, err
+		}
+
+		if event != windows.WAIT_OBJECT_0 {
+			return false, nil
+		}
+
+		var numRecords uint32
+		ok, _, err := procPeekConsoleInput.Call(uintptr(console), uintptr(unsafe.Pointer(&records[0])), uintptr(len(records)), uintptr(unsafe.Pointer(&numRecords)))
+		if ok == 0 {
+			return false, err
+		}
+
+		for _, record := range records[:numRecords] {
+			if record.isCharacter() {
+				return true, nil
+			}
+		}
+
+		ok, _, err = procReadConsoleInput.Call(uintptr(console), uintptr(unsafe.Pointer(&records[0])), uintptr(numRecords), uintptr(unsafe.Pointer(&numRecords)))
+		if ok == 0 {
+			return false, err
+		}
+	}
+}
+
+// Close stops the read that is in progress, if any. It doesn't close the console.
+func (t *ttyReader) Close() error {
+	err := windows.SetEvent(t.closed)
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	t.isClosed = true
+	return errors.Join(err, windows.CloseHandle(t.closed))
+}
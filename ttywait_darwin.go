@@ -0,0 +1,26 @@
+package prompt
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// waitForInput waits until one of the file descriptors can be read, and returns whether the first one can be. The
+// terminal is waited on with select since poll doesn't support devices on macOS.
+func waitForInput(fd, wakeFd int) (bool, error) {
+	for {
+		var fds unix.FdSet
+		fds.Set(fd)
+		fds.Set(wakeFd)
+
+		_, err := unix.Select(max(fd, wakeFd)+1, &fds, nil, nil, nil)
+		if err == unix.EINTR {
+			continue
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		return !fds.IsSet(wakeFd) && fds.IsSet(fd), nil
+	}
+}
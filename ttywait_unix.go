@@ -0,0 +1,28 @@
+//go:build !windows && !darwin
+
+package prompt
+
+import (
+	"golang.org/x/sys/unix"
+)
+
+// waitForInput waits until one of the file descriptors can be read, and returns whether the first one can be.
+func waitForInput(fd, wakeFd int) (bool, error) {
+	fds := []unix.PollFd{
+		{Fd: int32(fd), Events: unix.POLLIN},
+		{Fd: int32(wakeFd), Events: unix.POLLIN},
+	}
+
+	for {
+		_, err := unix.Poll(fds, -1)
+		if err == unix.EINTR {
+			continue
+		}
+
+		if err != nil {
+			return false, err
+		}
+
+		return fds[1].Revents == 0 && fds[0].Revents != 0, nil
+	}
+}
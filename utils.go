@@ -2,7 +2,8 @@ package prompt
 
 import (
 	"github.com/rivo/uniseg"
-	"strings"
+	"unicode"
+	"unicode/utf8"
 )
 
 func min(a, b int) int {
@@ -29,30 +30,93 @@ func abs(value int) int {
 	return value
 }
 
+// wrapString splits the string into lines that each take up at most width columns, breaking between words where it
+// can.
 func wrapString(toWrap string, width int) []string {
-	if len(toWrap) == 0 {
-		return []string{""}
+	ranges := wrapRanges(toWrap, width, width)
+
+	wrapped := make([]string, 0, len(ranges))
+	for _, r := range ranges {
+		wrapped = append(wrapped, toWrap[r.start:r.end])
 	}
 
-	wrapped := make([]string, 0, (len(toWrap)/width)+1)
-	gc := uniseg.NewGraphemes(toWrap)
+	return wrapped
+}
+
+// textRange is the part of a string between two byte offsets.
+type textRange struct {
+	start, end int
+}
+
+// wrapRanges splits the text into lines that take up at most firstWidth columns on the first line and width columns on
+// the rest. Lines are broken where the Unicode line breaking rules allow, such as after spaces and hyphens or between
+// ideographs, and a word is only split when it doesn't fit on a line by itself. Spaces at the end of a line are left
+// out of it and newlines always end a line.
+func wrapRanges(text string, firstWidth, width int) []textRange {
+	var lines []textRange
+	limit := max(1, firstWidth)
+
+	lineStart := 0
+	lineWidth := 0
+
+	// The end of the last cluster on the line that isn't a space
+	visibleEnd := 0
+
+	// The last place that the line can be broken, and where the line ends if it is broken there
+	breakStart, breakEnd := -1, 0
 
-	current := strings.Builder{}
-	currentLength := 0
+	gc := uniseg.NewGraphemes(text)
 	for gc.Next() {
-		current.WriteString(gc.Str())
-		currentLength++
+		from, to := gc.Positions()
+		cluster := gc.Str()
 
-		if currentLength == width {
-			wrapped = append(wrapped, current.String())
-			current.Reset()
-			currentLength = 0
+		if cluster == "\n" || cluster == "\r\n" {
+			lines = append(lines, textRange{lineStart, visibleEnd})
+			lineStart, lineWidth, visibleEnd, breakStart = to, 0, to, -1
+			limit = max(1, width)
+			continue
+		}
+
+		// Spaces can run past the end of the line since they are left out of it when it is broken.
+		r, _ := utf8.DecodeRuneInString(cluster)
+		if !unicode.IsSpace(r) {
+			for lineWidth+gc.Width() > limit {
+				if breakStart > lineStart {
+					lines = append(lines, textRange{lineStart, breakEnd})
+					lineStart, lineWidth = breakStart, uniseg.StringWidth(text[breakStart:from])
+				} else if len(lines) == 0 && limit < width {
+					// The first line is shorter than the rest, so start on the next line rather than splitting a word.
+					lines = append(lines, textRange{lineStart, lineStart})
+				} else if visibleEnd > lineStart {
+					lines = append(lines, textRange{lineStart, visibleEnd})
+					lineStart, lineWidth = from, 0
+				} else {
+					break
+				}
+
+				limit = max(1, width)
+				breakStart = -1
+			}
+
+			visibleEnd = to
+		}
+
+		lineWidth += gc.Width()
+		if gc.LineBreak() == uniseg.LineCanBreak {
+			breakStart, breakEnd = to, visibleEnd
 		}
 	}
 
-	if currentLength > 0 {
-		wrapped = append(wrapped, current.String())
+	return append(lines, textRange{lineStart, max(lineStart, visibleEnd)})
+}
+
+// firstGraphemes returns the first n grapheme clusters of the string.
+func firstGraphemes(s string, n int) string {
+	end := 0
+	gc := uniseg.NewGraphemes(s)
+	for i := 0; i < n && gc.Next(); i++ {
+		_, end = gc.Positions()
 	}
 
-	return wrapped
+	return s[:end]
 }